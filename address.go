@@ -0,0 +1,101 @@
+package email
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// splitAddress 拆分邮件地址为本地部分和域名
+func splitAddress(addr string) (string, string, bool) {
+	at := strings.LastIndex(addr, "@")
+	if at <= 0 || at == len(addr)-1 {
+		return "", "", false
+	}
+	return addr[:at], addr[at+1:], true
+}
+
+// isASCII 是否仅包含 ASCII 字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// toASCIIAddress 将国际化域名转换为 punycode（如 用户@例子.公司 的域名部分转为 xn--）
+// 本地部分包含非 ASCII 字符时无法转换，返回 ErrInvalidRecipient
+func toASCIIAddress(addr string) (string, error) {
+	if isASCII(addr) {
+		return addr, nil
+	}
+
+	local, domain, ok := splitAddress(addr)
+	if !ok {
+		return "", ErrInvalidRecipient.WithMsgf("邮件地址格式无效: %s", addr)
+	}
+	if !isASCII(local) {
+		return "", ErrInvalidRecipient.WithMsgf("服务器不支持 SMTPUTF8，无法投递非 ASCII 本地部分的地址: %s", addr)
+	}
+
+	asciiDomain, err := toASCIIDomain(domain)
+	if err != nil {
+		return "", ErrInvalidRecipient.Wrap(err).WithMsgf("国际化域名转换失败: %s", addr)
+	}
+	return local + "@" + asciiDomain, nil
+}
+
+// toASCIIDomain 将国际化域名转换为 punycode
+func toASCIIDomain(domain string) (string, error) {
+	if isASCII(domain) {
+		return domain, nil
+	}
+	return idna.Lookup.ToASCII(domain)
+}
+
+// headerAddress 生成邮件头中使用的地址
+// 本地部分为 ASCII 时域名统一转为 punycode，保证不支持 SMTPUTF8 的链路也能正确解析；
+// 否则保留 UTF-8 原文（RFC 6532）
+func headerAddress(addr string) string {
+	if ascii, err := toASCIIAddress(addr); err == nil {
+		return ascii
+	}
+	return addr
+}
+
+// displayAddress 生成带显示名的发件人地址（如 "Doe, John" <john@example.com>），
+// 显示名按需加引号或进行 RFC 2047 编码，供需要完整 From 字符串的 API 驱动使用
+func displayAddress(name, addr string) string {
+	if name == "" {
+		return headerAddress(addr)
+	}
+	return (&mail.Address{Name: name, Address: headerAddress(addr)}).String()
+}
+
+// headerAddresses 批量生成邮件头中使用的地址
+func headerAddresses(addrs []string) []string {
+	result := make([]string, len(addrs))
+	for i, addr := range addrs {
+		result[i] = headerAddress(addr)
+	}
+	return result
+}
+
+// verpAddress 将收件人编码进信封发件人（VERP）
+// 例如 bounces@ours.com + user@example.com => bounces+user=example.com@ours.com
+func verpAddress(sender, rcpt string) string {
+	local, domain, ok := splitAddress(sender)
+	if !ok {
+		return sender
+	}
+	rcptLocal, rcptDomain, ok := splitAddress(rcpt)
+	if !ok {
+		return sender
+	}
+	return fmt.Sprintf("%s+%s=%s@%s", local, rcptLocal, rcptDomain, domain)
+}
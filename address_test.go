@@ -0,0 +1,75 @@
+package email
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestToASCIIAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{name: "ascii", addr: "user@example.com", want: "user@example.com"},
+		{name: "idn domain", addr: "user@例子.公司", want: "user@xn--fsqu00a.xn--55qx5d"},
+		{name: "non-ascii local part", addr: "用户@例子.公司", wantErr: true},
+		{name: "missing domain", addr: "用户@", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toASCIIAddress(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toASCIIAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRecipient) {
+					t.Errorf("expected ErrInvalidRecipient, got %v", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("toASCIIAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderAddress(t *testing.T) {
+	if got := headerAddress("user@例子.公司"); got != "user@xn--fsqu00a.xn--55qx5d" {
+		t.Errorf("expected punycode domain, got %q", got)
+	}
+	if got := headerAddress("用户@例子.公司"); got != "用户@例子.公司" {
+		t.Errorf("expected UTF-8 address to be kept, got %q", got)
+	}
+}
+
+func TestDisplayAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want string
+	}{
+		{"", "sender@example.com", "sender@example.com"},
+		{"Example", "sender@example.com", `"Example" <sender@example.com>`},
+		{"Doe, John", "sender@example.com", `"Doe, John" <sender@example.com>`},
+		{`Say "Hi"`, "sender@example.com", `"Say \"Hi\"" <sender@example.com>`},
+		{"张三", "sender@例子.公司", "=?utf-8?q?=E5=BC=A0=E4=B8=89?= <sender@xn--fsqu00a.xn--55qx5d>"},
+	}
+	for _, tt := range tests {
+		if got := displayAddress(tt.name, tt.addr); got != tt.want {
+			t.Errorf("displayAddress(%q, %q) = %q, want %q", tt.name, tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestVerpAddress(t *testing.T) {
+	if got := verpAddress("bounces@ours.com", "user@example.com"); got != "bounces+user=example.com@ours.com" {
+		t.Errorf("verpAddress() = %q", got)
+	}
+	if got := verpAddress("invalid", "user@example.com"); got != "invalid" {
+		t.Errorf("expected sender to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,199 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// sniffLen http.DetectContentType 使用的最大字节数
+const sniffLen = 512
+
+// Open 打开附件内容，调用方负责关闭
+// 优先级: Path > Reader > Content；可 Seek 的 Reader 在再次打开时回到初始位置，
+// 其余 Reader 只能读取一次
+func (a *Attachment) Open() (io.ReadCloser, error) {
+	switch {
+	case a.Path != "":
+		f, err := os.Open(a.Path)
+		if err != nil {
+			return nil, ErrInvalidMessage.Wrap(err).WithMsgf("打开附件失败: %s", a.Filename)
+		}
+		return f, nil
+	case a.Reader != nil:
+		seeker, seekable := a.Reader.(io.Seeker)
+		if !a.readerUsed {
+			a.readerUsed = true
+			if seekable {
+				offset, err := seeker.Seek(0, io.SeekCurrent)
+				if err != nil {
+					return nil, ErrInvalidMessage.Wrap(err).WithMsgf("读取附件失败: %s", a.Filename)
+				}
+				a.readerOffset = offset
+			}
+			return io.NopCloser(a.Reader), nil
+		}
+		if !seekable {
+			return nil, ErrInvalidMessage.WithMsgf("附件 %s 的 Reader 不支持重复读取", a.Filename)
+		}
+		if _, err := seeker.Seek(a.readerOffset, io.SeekStart); err != nil {
+			return nil, ErrInvalidMessage.Wrap(err).WithMsgf("读取附件失败: %s", a.Filename)
+		}
+		return io.NopCloser(a.Reader), nil
+	default:
+		return io.NopCloser(bytes.NewReader(a.Content)), nil
+	}
+}
+
+// rereadable 附件内容能否多次读取（不可 Seek 的 Reader 只能读取一次）
+func (a *Attachment) rereadable() bool {
+	if a.Path != "" || a.Reader == nil {
+		return true
+	}
+	_, ok := a.Reader.(io.Seeker)
+	return ok
+}
+
+// ReadAll 读取全部附件内容（用于需要一次性提交内容的 HTTP API 驱动）
+func (a *Attachment) ReadAll() ([]byte, error) {
+	if a.Path == "" && a.Reader == nil {
+		return a.Content, nil
+	}
+	rc, err := a.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, ErrInvalidMessage.Wrap(err).WithMsgf("读取附件失败: %s", a.Filename)
+	}
+	return data, nil
+}
+
+// ContentSize 获取附件原始内容大小，无法预知时返回 -1
+func (a *Attachment) ContentSize() (int64, error) {
+	switch {
+	case a.Path != "":
+		info, err := os.Stat(a.Path)
+		if err != nil {
+			return 0, ErrInvalidMessage.Wrap(err).WithMsgf("读取附件失败: %s", a.Filename)
+		}
+		return info.Size(), nil
+	case a.Reader != nil:
+		if a.Size > 0 {
+			return a.Size, nil
+		}
+		if r, ok := a.Reader.(interface{ Len() int }); ok {
+			return int64(r.Len()), nil
+		}
+		return -1, nil
+	default:
+		return int64(len(a.Content)), nil
+	}
+}
+
+// DetectContentType 未设置 ContentType 时推断 MIME 类型：
+// 优先按文件扩展名，无法识别时按内容前 512 字节嗅探
+func (a *Attachment) DetectContentType() error {
+	if a.ContentType != "" {
+		return nil
+	}
+
+	name := a.Filename
+	if name == "" {
+		name = a.Path
+	}
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		a.ContentType = contentType
+		return nil
+	}
+
+	head, err := a.head()
+	if err != nil {
+		return err
+	}
+	a.ContentType = http.DetectContentType(head)
+	return nil
+}
+
+// head 读取内容开头用于类型嗅探，不影响后续读取
+func (a *Attachment) head() ([]byte, error) {
+	switch {
+	case a.Path != "":
+		f, err := os.Open(a.Path)
+		if err != nil {
+			return nil, ErrInvalidMessage.Wrap(err).WithMsgf("打开附件失败: %s", a.Filename)
+		}
+		defer f.Close()
+		return readHead(f, a.Filename)
+	case a.Reader != nil:
+		// 可 Seek 的 Reader 读取后回到原位置，其余 Reader 将已读内容拼接回去
+		if seeker, ok := a.Reader.(io.Seeker); ok {
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, ErrInvalidMessage.Wrap(err).WithMsgf("读取附件失败: %s", a.Filename)
+			}
+			head, err := readHead(a.Reader, a.Filename)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				return nil, ErrInvalidMessage.Wrap(err).WithMsgf("读取附件失败: %s", a.Filename)
+			}
+			return head, nil
+		}
+		size, err := a.ContentSize()
+		if err != nil {
+			return nil, err
+		}
+		head, err := readHead(a.Reader, a.Filename)
+		if err != nil {
+			return nil, err
+		}
+		a.Reader = io.MultiReader(bytes.NewReader(head), a.Reader)
+		if size >= 0 {
+			// 保留原 Reader 的长度，嗅探后仍可在发送前检查大小
+			a.Reader = &sniffedReader{Reader: a.Reader, n: size}
+		}
+		return head, nil
+	default:
+		if len(a.Content) > sniffLen {
+			return a.Content[:sniffLen], nil
+		}
+		return a.Content, nil
+	}
+}
+
+// sniffedReader 拼接了嗅探内容的 Reader，通过 Len 报告剩余字节数（见 ContentSize）
+type sniffedReader struct {
+	io.Reader
+	n int64
+}
+
+// Read 实现 io.Reader
+func (r *sniffedReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n -= int64(n)
+	return n, err
+}
+
+// Len 剩余字节数
+func (r *sniffedReader) Len() int {
+	return int(max(r.n, 0))
+}
+
+// readHead 读取最多 sniffLen 字节
+func readHead(r io.Reader, name string) ([]byte, error) {
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrInvalidMessage.Wrap(err).WithMsgf("读取附件失败: %s", name)
+	}
+	return buf[:n], nil
+}
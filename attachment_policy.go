@@ -0,0 +1,160 @@
+package email
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// AttachmentPolicy 附件策略，零值表示不限制
+type AttachmentPolicy struct {
+	// AllowExtensions 允许的扩展名（如 ".pdf"、"csv"），为空时不限制
+	AllowExtensions []string `mapstructure:"allow_extensions"`
+
+	// DenyExtensions 禁止的扩展名，优先于 AllowExtensions
+	DenyExtensions []string `mapstructure:"deny_extensions"`
+
+	// AllowTypes 允许的 MIME 类型，支持 "image/*" 通配，为空时不限制
+	AllowTypes []string `mapstructure:"allow_types"`
+
+	// DenyTypes 禁止的 MIME 类型，优先于 AllowTypes
+	DenyTypes []string `mapstructure:"deny_types"`
+
+	// MaxSize 单个附件最大字节数（支持 "10MB" 等写法），0 表示不限制
+	MaxSize ByteSize `mapstructure:"max_size"`
+
+	// MaxTotalSize 附件总字节数上限（支持 "20MB" 等写法），0 表示不限制
+	MaxTotalSize ByteSize `mapstructure:"max_total_size"`
+
+	// MaxCount 附件数量上限，0 表示不限制
+	MaxCount int `mapstructure:"max_count"`
+}
+
+// Validate 验证策略配置
+func (p *AttachmentPolicy) Validate() error {
+	if p.MaxSize < 0 || p.MaxTotalSize < 0 || p.MaxCount < 0 {
+		return fmt.Errorf("attachment limits cannot be negative")
+	}
+	return nil
+}
+
+// Check 检查附件是否符合策略
+// 附件类型需已确定（见 Message.DetectContentTypes），设置了大小限制时流式附件须能预知大小
+func (p *AttachmentPolicy) Check(attachments []Attachment) error {
+	if p.MaxCount > 0 && len(attachments) > p.MaxCount {
+		return ErrAttachmentRejected.WithMsgf("附件数量 %d 超过上限 %d", len(attachments), p.MaxCount)
+	}
+
+	var total int64
+	for i := range attachments {
+		att := &attachments[i]
+		name := attachmentName(att)
+		if err := p.checkType(att); err != nil {
+			return err
+		}
+
+		// 大小
+		if p.MaxSize == 0 && p.MaxTotalSize == 0 {
+			continue
+		}
+		size, err := att.ContentSize()
+		if err != nil {
+			return err
+		}
+		if size < 0 {
+			return ErrAttachmentRejected.WithMsgf("附件 %s 大小未知，无法校验大小限制（请设置 Attachment.Size）", name)
+		}
+		if p.MaxSize > 0 && size > int64(p.MaxSize) {
+			return ErrAttachmentTooLarge.WithMsgf("附件 %s 大小 %d 字节超过上限 %d 字节", name, size, p.MaxSize)
+		}
+		total += size
+		if p.MaxTotalSize > 0 && total > int64(p.MaxTotalSize) {
+			return ErrAttachmentTooLarge.WithMsgf("附件总大小超过上限 %d 字节（超出于附件 %s）", p.MaxTotalSize, name)
+		}
+	}
+	return nil
+}
+
+// checkTypes 只检查扩展名与 MIME 类型
+// 用于附件转换之前：压缩包中的每个原始附件同样受类型限制，大小与数量按转换后的附件检查
+func (p *AttachmentPolicy) checkTypes(attachments []Attachment) error {
+	for i := range attachments {
+		if err := p.checkType(&attachments[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkType 检查单个附件的扩展名与 MIME 类型
+func (p *AttachmentPolicy) checkType(att *Attachment) error {
+	name := attachmentName(att)
+
+	// 扩展名
+	ext := normalizeExtension(filepath.Ext(name))
+	if matchExtension(p.DenyExtensions, ext) {
+		return ErrAttachmentRejected.WithMsgf("附件 %s 的扩展名 %s 被禁止", name, ext)
+	}
+	if len(p.AllowExtensions) > 0 && !matchExtension(p.AllowExtensions, ext) {
+		return ErrAttachmentRejected.WithMsgf("附件 %s 的扩展名 %s 不在允许列表中", name, ext)
+	}
+
+	// MIME 类型
+	mediaType := normalizeMediaType(att.ContentType)
+	if matchMediaType(p.DenyTypes, mediaType) {
+		return ErrAttachmentRejected.WithMsgf("附件 %s 的类型 %s 被禁止", name, mediaType)
+	}
+	if len(p.AllowTypes) > 0 && !matchMediaType(p.AllowTypes, mediaType) {
+		return ErrAttachmentRejected.WithMsgf("附件 %s 的类型 %s 不在允许列表中", name, mediaType)
+	}
+	return nil
+}
+
+// attachmentName 附件名称，未设置 Filename 时使用文件名
+func attachmentName(att *Attachment) string {
+	if att.Filename == "" {
+		return filepath.Base(att.Path)
+	}
+	return att.Filename
+}
+
+// normalizeExtension 统一扩展名格式（小写，带前导点）
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// matchExtension 扩展名是否在列表中
+func matchExtension(list []string, ext string) bool {
+	for _, item := range list {
+		if normalizeExtension(item) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeMediaType 去除参数并转为小写（如 "text/html; charset=utf-8" -> "text/html"）
+func normalizeMediaType(contentType string) string {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// matchMediaType MIME 类型是否匹配列表（支持 "type/*" 通配）
+func matchMediaType(list []string, mediaType string) bool {
+	for _, item := range list {
+		pattern := normalizeMediaType(item)
+		if pattern == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KOMKZ/go-yogan-framework/logger"
+)
+
+func TestAttachmentPolicy_Check(t *testing.T) {
+	pdf := Attachment{Filename: "report.pdf", Content: make([]byte, 100), ContentType: "application/pdf"}
+	png := Attachment{Filename: "logo.png", Content: make([]byte, 50), ContentType: "image/png"}
+	exe := Attachment{Filename: "setup.EXE", Content: make([]byte, 10), ContentType: "application/octet-stream"}
+
+	tests := []struct {
+		name        string
+		policy      AttachmentPolicy
+		attachments []Attachment
+		wantErr     error
+		wantMsg     string
+	}{
+		{
+			name:        "empty policy",
+			attachments: []Attachment{pdf, png, exe},
+		},
+		{
+			name:        "deny extension",
+			policy:      AttachmentPolicy{DenyExtensions: []string{"exe", ".bat"}},
+			attachments: []Attachment{pdf, exe},
+			wantErr:     ErrAttachmentRejected,
+			wantMsg:     "setup.EXE",
+		},
+		{
+			name:        "allow extension",
+			policy:      AttachmentPolicy{AllowExtensions: []string{".pdf", ".PNG"}},
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:        "extension not allowed",
+			policy:      AttachmentPolicy{AllowExtensions: []string{".pdf"}},
+			attachments: []Attachment{pdf, png},
+			wantErr:     ErrAttachmentRejected,
+			wantMsg:     "logo.png",
+		},
+		{
+			name:        "allow type wildcard",
+			policy:      AttachmentPolicy{AllowTypes: []string{"image/*", "application/pdf"}},
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:        "deny type overrides allow",
+			policy:      AttachmentPolicy{AllowTypes: []string{"image/*"}, DenyTypes: []string{"image/png"}},
+			attachments: []Attachment{png},
+			wantErr:     ErrAttachmentRejected,
+			wantMsg:     "logo.png",
+		},
+		{
+			name:        "type parameters ignored",
+			policy:      AttachmentPolicy{AllowTypes: []string{"text/html"}},
+			attachments: []Attachment{{Filename: "page.html", ContentType: "text/html; charset=utf-8"}},
+		},
+		{
+			name:        "max count",
+			policy:      AttachmentPolicy{MaxCount: 1},
+			attachments: []Attachment{pdf, png},
+			wantErr:     ErrAttachmentRejected,
+		},
+		{
+			name:        "max size",
+			policy:      AttachmentPolicy{MaxSize: 80},
+			attachments: []Attachment{png, pdf},
+			wantErr:     ErrAttachmentTooLarge,
+			wantMsg:     "report.pdf",
+		},
+		{
+			name:        "max total size",
+			policy:      AttachmentPolicy{MaxTotalSize: 120},
+			attachments: []Attachment{pdf, png},
+			wantErr:     ErrAttachmentTooLarge,
+			wantMsg:     "logo.png",
+		},
+		{
+			name:        "unknown reader size with size limit",
+			policy:      AttachmentPolicy{MaxSize: 1024},
+			attachments: []Attachment{{Filename: "data.bin", Reader: &onceReader{r: strings.NewReader("x")}}},
+			wantErr:     ErrAttachmentRejected,
+			wantMsg:     "data.bin",
+		},
+		{
+			name:        "declared reader size",
+			policy:      AttachmentPolicy{MaxSize: 1024},
+			attachments: []Attachment{{Filename: "data.bin", Reader: &onceReader{r: strings.NewReader("x")}, Size: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.attachments)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("expected error to name %q, got %v", tt.wantMsg, err)
+			}
+		})
+	}
+}
+
+func TestAttachmentPolicy_Validate(t *testing.T) {
+	policy := &AttachmentPolicy{MaxSize: -1}
+	if err := policy.Validate(); err == nil {
+		t.Error("expected error for negative limit")
+	}
+}
+
+func TestBuilder_AttachmentPolicy(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	mockDriver := &MockDriver{name: "mock", sendResult: &Result{Success: true}}
+	registry := NewRegistry()
+	registry.Register("mock", func(config map[string]any) (Driver, error) {
+		return mockDriver, nil
+	})
+
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+		Attachments: AttachmentPolicy{
+			DenyTypes: []string{"application/pdf"},
+		},
+	}
+
+	manager, err := NewManager(config, log, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 类型由扩展名推断后再检查策略
+	_, err = manager.New().
+		To("user@example.com").
+		Subject("Test").
+		Body("Hello").
+		Attach("invoice.pdf", []byte("%PDF-1.4")).
+		Send(context.Background())
+	if !errors.Is(err, ErrAttachmentRejected) {
+		t.Fatalf("expected ErrAttachmentRejected, got %v", err)
+	}
+
+	_, err = manager.New().
+		To("user@example.com").
+		Subject("Test").
+		Body("Hello").
+		Attach("notes.txt", []byte("hello")).
+		Send(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestManager_Send_AttachmentPolicy(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	mockDriver := &MockDriver{name: "mock", sendResult: &Result{Success: true}}
+	registry := NewRegistry()
+	registry.Register("mock", func(config map[string]any) (Driver, error) {
+		return mockDriver, nil
+	})
+
+	var maxSize ByteSize
+	if err := maxSize.UnmarshalText([]byte("1KB")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+		Attachments: AttachmentPolicy{MaxSize: maxSize},
+	}
+
+	manager, err := NewManager(config, log, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = manager.Send(context.Background(), "", &Message{
+		From:        "sender@example.com",
+		To:          []string{"user@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "big.txt", Content: []byte(strings.Repeat("a", 2048))}},
+	})
+	if !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Fatalf("expected ErrAttachmentTooLarge, got %v", err)
+	}
+
+	// 不可 Seek 的 Reader 在类型嗅探后仍能检查大小
+	_, err = manager.Send(context.Background(), "mock", &Message{
+		From:        "sender@example.com",
+		To:          []string{"user@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "data", Reader: bytes.NewBufferString("small")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
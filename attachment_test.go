@@ -0,0 +1,203 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// onceReader 不支持 Seek 的 Reader
+type onceReader struct {
+	r io.Reader
+}
+
+func (o *onceReader) Read(p []byte) (int, error) {
+	return o.r.Read(p)
+}
+
+func readAttachment(t *testing.T, att *Attachment) (string, error) {
+	t.Helper()
+	rc, err := att.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read attachment: %v", err)
+	}
+	return string(data), nil
+}
+
+func TestAttachment_Open(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(path, []byte("a,b,c"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		att  *Attachment
+		want string
+	}{
+		{"content", &Attachment{Content: []byte("bytes")}, "bytes"},
+		{"path", &Attachment{Path: path, Content: []byte("ignored")}, "a,b,c"},
+		{"seekable reader", &Attachment{Reader: strings.NewReader("stream")}, "stream"},
+		{"reader", &Attachment{Reader: &onceReader{r: strings.NewReader("once")}}, "once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAttachment(t, tt.att)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAttachment_Open_Reopen(t *testing.T) {
+	t.Run("seekable reader rewinds to initial offset", func(t *testing.T) {
+		r := bytes.NewReader([]byte("skip:payload"))
+		r.Seek(5, io.SeekStart)
+		att := &Attachment{Filename: "a.txt", Reader: r}
+
+		for i := 0; i < 2; i++ {
+			got, err := readAttachment(t, att)
+			if err != nil {
+				t.Fatalf("read %d: unexpected error: %v", i, err)
+			}
+			if got != "payload" {
+				t.Errorf("read %d: expected %q, got %q", i, "payload", got)
+			}
+		}
+	})
+
+	t.Run("non-seekable reader is single use", func(t *testing.T) {
+		att := &Attachment{Filename: "a.txt", Reader: &onceReader{r: strings.NewReader("once")}}
+		if _, err := readAttachment(t, att); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := att.Open(); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("expected ErrInvalidMessage on reuse, got %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		att := &Attachment{Filename: "gone.txt", Path: filepath.Join(t.TempDir(), "gone.txt")}
+		if _, err := att.Open(); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("expected ErrInvalidMessage, got %v", err)
+		}
+	})
+}
+
+func TestAttachment_ContentSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, make([]byte, 300), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		att  *Attachment
+		want int64
+	}{
+		{"content", &Attachment{Content: []byte("12345")}, 5},
+		{"path", &Attachment{Path: path}, 300},
+		{"reader with Len", &Attachment{Reader: strings.NewReader("1234")}, 4},
+		{"reader with Size", &Attachment{Reader: &onceReader{r: strings.NewReader("12")}, Size: 2}, 2},
+		{"unknown reader", &Attachment{Reader: &onceReader{r: strings.NewReader("12")}}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.att.ContentSize()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAttachment_DetectContentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16))
+	path := filepath.Join(t.TempDir(), "image")
+	if err := os.WriteFile(path, png, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		att  *Attachment
+		want string
+	}{
+		{"explicit type kept", &Attachment{Filename: "a.pdf", ContentType: "application/x-custom"}, "application/x-custom"},
+		{"by extension", &Attachment{Filename: "report.pdf", Content: []byte("data")}, "application/pdf"},
+		{"by extension uppercase", &Attachment{Filename: "LOGO.PNG"}, "image/png"},
+		{"sniff content", &Attachment{Filename: "blob", Content: png}, "image/png"},
+		{"sniff file", &Attachment{Filename: "image", Path: path}, "image/png"},
+		{"sniff reader", &Attachment{Filename: "page", Reader: strings.NewReader("<html><body>hi</body></html>")}, "text/html; charset=utf-8"},
+		{"unknown binary", &Attachment{Filename: "data", Content: []byte{0x00, 0x01, 0x02}}, "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.att.DetectContentType(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.att.ContentType != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, tt.att.ContentType)
+			}
+		})
+	}
+}
+
+func TestAttachment_DetectContentType_PreservesReader(t *testing.T) {
+	content := "%PDF-1.4\n" + strings.Repeat("x", 1024)
+
+	t.Run("seekable", func(t *testing.T) {
+		att := &Attachment{Filename: "doc", Reader: strings.NewReader(content)}
+		if err := att.DetectContentType(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if att.ContentType != "application/pdf" {
+			t.Errorf("expected application/pdf, got %q", att.ContentType)
+		}
+		if got, _ := readAttachment(t, att); got != content {
+			t.Error("expected reader content to be preserved")
+		}
+	})
+
+	t.Run("non-seekable", func(t *testing.T) {
+		att := &Attachment{Filename: "doc", Reader: &onceReader{r: strings.NewReader(content)}}
+		if err := att.DetectContentType(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, _ := readAttachment(t, att); got != content {
+			t.Error("expected reader content to be preserved")
+		}
+	})
+
+	t.Run("non-seekable with length", func(t *testing.T) {
+		att := &Attachment{Filename: "doc", Reader: bytes.NewBufferString(content)}
+		if err := att.DetectContentType(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if size, _ := att.ContentSize(); size != int64(len(content)) {
+			t.Errorf("expected size %d after sniffing, got %d", len(content), size)
+		}
+		if got, _ := readAttachment(t, att); got != content {
+			t.Error("expected reader content to be preserved")
+		}
+	})
+}
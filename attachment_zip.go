@@ -0,0 +1,319 @@
+package email
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AttachmentTransformer 附件转换器，在驱动发送前调整消息附件
+// 返回的 cleanup 在发送结束后调用，用于释放临时资源（可为 nil）
+type AttachmentTransformer interface {
+	Transform(msg *Message) (cleanup func(), err error)
+}
+
+// ZipDefaultFilename 压缩包默认文件名
+const ZipDefaultFilename = "attachments.zip"
+
+// ZipConfig 附件压缩配置
+type ZipConfig struct {
+	// Enabled 是否启用
+	Enabled bool `mapstructure:"enabled"`
+
+	// Filename 压缩包文件名（默认 attachments.zip）
+	Filename string `mapstructure:"filename"`
+
+	// Password 压缩包密码（可选，设置后使用 WinZip AES-256 加密）
+	Password string `mapstructure:"password"`
+
+	// Threshold 超过该字节数的附件会被压缩（默认使用 attachments.max_size）
+	Threshold int64 `mapstructure:"threshold"`
+}
+
+// ZipTransformer 将选中或超过阈值的附件打包为一个 zip 附件，内联图片不受影响
+type ZipTransformer struct {
+	// Filename 压缩包文件名（默认 attachments.zip）
+	Filename string
+
+	// Password 压缩包密码（可选，设置后使用 WinZip AES-256 加密）
+	Password string
+
+	// Threshold 超过该字节数的附件会被压缩，0 表示不按大小选择
+	Threshold int64
+
+	// Select 自定义选择条件（可选），返回 true 的附件会被压缩
+	Select func(att *Attachment) bool
+}
+
+// Transform 打包附件，压缩包写入临时文件并以 AttachFile 的方式流式发送
+func (z *ZipTransformer) Transform(msg *Message) (func(), error) {
+	var selected []*Attachment
+	var kept []Attachment
+	for i := range msg.Attachments {
+		att := &msg.Attachments[i]
+		ok, err := z.selects(att)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, att)
+		} else {
+			kept = append(kept, *att)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.CreateTemp("", "email-*.zip")
+	if err != nil {
+		return nil, ErrInvalidMessage.Wrap(err).WithMsg("创建压缩包失败")
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+
+	if err := z.writeArchive(f, selected); err != nil {
+		f.Close()
+		cleanup()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return nil, ErrInvalidMessage.Wrap(err).WithMsg("写入压缩包失败")
+	}
+
+	filename := z.Filename
+	if filename == "" {
+		filename = ZipDefaultFilename
+	}
+	msg.Attachments = append(kept, Attachment{
+		Filename:    filename,
+		Path:        f.Name(),
+		ContentType: "application/zip",
+	})
+	return cleanup, nil
+}
+
+// selects 判断附件是否需要压缩
+func (z *ZipTransformer) selects(att *Attachment) (bool, error) {
+	if att.Inline {
+		return false, nil
+	}
+	if z.Select != nil && z.Select(att) {
+		return true, nil
+	}
+	if z.Threshold <= 0 {
+		return false, nil
+	}
+	size, err := att.ContentSize()
+	if err != nil {
+		return false, err
+	}
+	// 大小未知的流式附件按超过阈值处理
+	return size < 0 || size > z.Threshold, nil
+}
+
+// writeArchive 写入压缩包
+func (z *ZipTransformer) writeArchive(w io.Writer, attachments []*Attachment) error {
+	zw := zip.NewWriter(w)
+	names := make(map[string]int)
+	modified := time.Now()
+
+	for _, att := range attachments {
+		name := zipEntryName(att, names)
+		if err := z.writeEntry(zw, name, modified, att); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return ErrInvalidMessage.Wrap(err).WithMsg("写入压缩包失败")
+	}
+	return nil
+}
+
+// writeEntry 写入单个附件
+func (z *ZipTransformer) writeEntry(zw *zip.Writer, name string, modified time.Time, att *Attachment) error {
+	rc, err := att.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	r := attachmentReader{r: rc, name: att.Filename}
+
+	if z.Password != "" {
+		if err := writeAESEntry(zw, name, modified, z.Password, r); err != nil {
+			return wrapZipError(err)
+		}
+		return nil
+	}
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return wrapZipError(err)
+	}
+	if _, err := io.Copy(fw, r); err != nil {
+		return wrapZipError(err)
+	}
+	return nil
+}
+
+// wrapZipError 附件读取错误原样返回，其余错误包装为压缩失败
+func wrapZipError(err error) error {
+	if isAppError(err) {
+		return err
+	}
+	return ErrInvalidMessage.Wrap(err).WithMsg("写入压缩包失败")
+}
+
+// zipEntryName 生成压缩包内的文件名，重名时追加序号
+func zipEntryName(att *Attachment, names map[string]int) string {
+	name := att.Filename
+	if name == "" {
+		name = filepath.Base(att.Path)
+	}
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = filepath.Base(name)
+
+	names[name]++
+	if n := names[name]; n > 1 {
+		ext := filepath.Ext(name)
+		name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+	}
+	return name
+}
+
+// WinZip AES（AE-2）参数，见 https://www.winzip.com/en/support/aes-encryption/
+const (
+	winzipAESMethod     = 99
+	winzipAESExtraID    = 0x9901
+	winzipAESVersion    = 2 // AE-2: CRC 置 0，由 HMAC 校验完整性
+	winzipAESStrength   = 3 // AES-256
+	winzipAESKeyLen     = 32
+	winzipAESSaltLen    = 16
+	winzipAESAuthLen    = 10
+	winzipAESIterations = 1000
+)
+
+// writeAESEntry 写入 WinZip AES-256 加密的条目（先 deflate 压缩再加密）
+func writeAESEntry(zw *zip.Writer, name string, modified time.Time, password string, r io.Reader) error {
+	extra := []byte{
+		byte(winzipAESExtraID & 0xff), byte(winzipAESExtraID >> 8), 7, 0,
+		winzipAESVersion, 0, 'A', 'E', winzipAESStrength,
+		byte(zip.Deflate), 0,
+	}
+	fh := &zip.FileHeader{
+		Name:          name,
+		Method:        winzipAESMethod,
+		Flags:         0x1 | 0x8, // 加密，大小写入数据描述符
+		Extra:         extra,
+		ReaderVersion: 51,
+	}
+	fh.ModifiedDate, fh.ModifiedTime = msDosTime(modified)
+	if !isASCII(name) {
+		fh.Flags |= 0x800
+	}
+
+	w, err := zw.CreateRaw(fh)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, winzipAESSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key, err := pbkdf2.Key(sha1.New, password, salt, winzipAESIterations, 2*winzipAESKeyLen+2)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key[:winzipAESKeyLen])
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(salt); err != nil {
+		return err
+	}
+	if _, err := w.Write(key[2*winzipAESKeyLen:]); err != nil {
+		return err
+	}
+
+	ew := &winzipAESWriter{w: w, block: block, mac: hmac.New(sha1.New, key[winzipAESKeyLen:2*winzipAESKeyLen])}
+	cw := &countingWriter{w: ew}
+	fw, err := flate.NewWriter(cw, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(fw, r)
+	if err != nil {
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+	if _, err := w.Write(ew.mac.Sum(nil)[:winzipAESAuthLen]); err != nil {
+		return err
+	}
+
+	// 数据描述符与中央目录使用写入后的实际大小
+	fh.UncompressedSize64 = uint64(n)
+	fh.CompressedSize64 = uint64(winzipAESSaltLen + 2 + cw.n + winzipAESAuthLen)
+	fh.UncompressedSize = uint32(min(fh.UncompressedSize64, uint64(^uint32(0))))
+	fh.CompressedSize = uint32(min(fh.CompressedSize64, uint64(^uint32(0))))
+	return nil
+}
+
+// winzipAESWriter AES-CTR 加密写入器（计数器为小端序、从 1 开始），同时计算密文的 HMAC-SHA1
+type winzipAESWriter struct {
+	w       io.Writer
+	block   cipher.Block
+	mac     hash.Hash
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+}
+
+func (e *winzipAESWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	for i := range p {
+		if e.used == 0 || e.used == aes.BlockSize {
+			for j := range e.counter {
+				e.counter[j]++
+				if e.counter[j] != 0 {
+					break
+				}
+			}
+			e.block.Encrypt(e.stream[:], e.counter[:])
+			e.used = 0
+		}
+		buf[i] = p[i] ^ e.stream[e.used]
+		e.used++
+	}
+	e.mac.Write(buf)
+	if _, err := e.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// msDosTime 转换为 MS-DOS 日期与时间
+func msDosTime(t time.Time) (date, clock uint16) {
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, t.Location())
+	}
+	date = uint16((t.Year()-1980)<<9 | int(t.Month())<<5 | t.Day())
+	clock = uint16(t.Hour()<<11 | t.Minute()<<5 | t.Second()/2)
+	return date, clock
+}
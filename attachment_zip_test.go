@@ -0,0 +1,352 @@
+package email
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/KOMKZ/go-yogan-framework/logger"
+)
+
+// openZipAttachment 读取转换后的压缩包附件
+func openZipAttachment(t *testing.T, att *Attachment) *zip.Reader {
+	t.Helper()
+	data, err := att.ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	return zr
+}
+
+func TestZipTransformer_Transform(t *testing.T) {
+	msg := &Message{
+		Attachments: []Attachment{
+			{Filename: "small.txt", Content: []byte("small")},
+			{Filename: "export.csv", Content: bytes.Repeat([]byte("a,b,c\n"), 100)},
+			{Filename: "logo.png", Content: bytes.Repeat([]byte{0x89}, 1000), Inline: true, ContentID: "logo"},
+			{Filename: "report.pdf", Content: []byte("%PDF-1.4")},
+		},
+	}
+
+	transformer := &ZipTransformer{
+		Filename:  "bundle.zip",
+		Threshold: 100,
+		Select: func(att *Attachment) bool {
+			return strings.HasSuffix(att.Filename, ".pdf")
+		},
+	}
+	cleanup, err := transformer.Transform(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cleanup == nil {
+		t.Fatal("expected cleanup function")
+	}
+
+	if len(msg.Attachments) != 3 {
+		t.Fatalf("expected 3 attachments, got %d", len(msg.Attachments))
+	}
+	if msg.Attachments[0].Filename != "small.txt" || msg.Attachments[1].ContentID != "logo" {
+		t.Errorf("expected small and inline attachments to be kept, got %+v", msg.Attachments[:2])
+	}
+
+	archive := msg.Attachments[2]
+	if archive.Filename != "bundle.zip" || archive.ContentType != "application/zip" {
+		t.Errorf("unexpected archive attachment: %+v", archive)
+	}
+
+	zr := openZipAttachment(t, &archive)
+	got := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(data)
+	}
+	if got["export.csv"] != strings.Repeat("a,b,c\n", 100) || got["report.pdf"] != "%PDF-1.4" {
+		t.Errorf("unexpected archive entries: %v", got)
+	}
+
+	cleanup()
+	if _, err := os.Stat(archive.Path); !os.IsNotExist(err) {
+		t.Errorf("expected temp file to be removed, got %v", err)
+	}
+}
+
+func TestZipTransformer_NothingSelected(t *testing.T) {
+	msg := &Message{Attachments: []Attachment{{Filename: "a.txt", Content: []byte("a")}}}
+
+	cleanup, err := (&ZipTransformer{Threshold: 1024}).Transform(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cleanup != nil || len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "a.txt" {
+		t.Errorf("expected message to be unchanged, got %+v", msg.Attachments)
+	}
+}
+
+func TestZipTransformer_DuplicateNames(t *testing.T) {
+	msg := &Message{Attachments: []Attachment{
+		{Filename: "data.csv", Content: []byte("1")},
+		{Filename: "data.csv", Content: []byte("2")},
+	}}
+
+	cleanup, err := (&ZipTransformer{Select: func(*Attachment) bool { return true }}).Transform(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	zr := openZipAttachment(t, &msg.Attachments[0])
+	if len(zr.File) != 2 || zr.File[0].Name != "data.csv" || zr.File[1].Name != "data (2).csv" {
+		t.Errorf("unexpected entry names: %v, %v", zr.File[0].Name, zr.File[1].Name)
+	}
+}
+
+// decryptWinZipAES 按 AE-2 规范解密条目，用于校验加密实现
+func decryptWinZipAES(t *testing.T, f *zip.File, password string) (string, error) {
+	t.Helper()
+	if f.Method != winzipAESMethod || f.Flags&0x1 == 0 {
+		t.Fatalf("expected AES encrypted entry, got method %d flags %#x", f.Method, f.Flags)
+	}
+	if !bytes.Contains(f.Extra, []byte{0x01, 0x99, 7, 0, 2, 0, 'A', 'E', 3, 8, 0}) {
+		t.Fatalf("expected AES extra field, got %x", f.Extra)
+	}
+
+	rc, err := f.OpenRaw()
+	if err != nil {
+		t.Fatalf("failed to open raw: %v", err)
+	}
+	raw, _ := io.ReadAll(rc)
+	salt, verifier := raw[:16], raw[16:18]
+	data, auth := raw[18:len(raw)-10], raw[len(raw)-10:]
+
+	key, _ := pbkdf2.Key(sha1.New, password, salt, 1000, 66)
+	if !bytes.Equal(key[64:], verifier) {
+		return "", errors.New("wrong password")
+	}
+	mac := hmac.New(sha1.New, key[32:64])
+	mac.Write(data)
+	if !bytes.Equal(mac.Sum(nil)[:10], auth) {
+		return "", errors.New("authentication failed")
+	}
+
+	block, _ := aes.NewCipher(key[:32])
+	plain := make([]byte, len(data))
+	var counter, stream [16]byte
+	for i := range data {
+		if i%16 == 0 {
+			for j := range counter {
+				counter[j]++
+				if counter[j] != 0 {
+					break
+				}
+			}
+			block.Encrypt(stream[:], counter[:])
+		}
+		plain[i] = data[i] ^ stream[i%16]
+	}
+
+	out, err := io.ReadAll(flate.NewReader(bytes.NewReader(plain)))
+	if err != nil {
+		return "", err
+	}
+	if uint64(len(out)) != f.UncompressedSize64 {
+		t.Errorf("expected uncompressed size %d, got %d", f.UncompressedSize64, len(out))
+	}
+	return string(out), nil
+}
+
+func TestZipTransformer_Password(t *testing.T) {
+	content := strings.Repeat("confidential export line\n", 500)
+	msg := &Message{Attachments: []Attachment{
+		{Filename: "工资单.csv", Reader: strings.NewReader(content)},
+	}}
+
+	cleanup, err := (&ZipTransformer{Password: "s3cret", Threshold: 10}).Transform(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	zr := openZipAttachment(t, &msg.Attachments[0])
+	if len(zr.File) != 1 || zr.File[0].Name != "工资单.csv" {
+		t.Fatalf("unexpected entries: %+v", zr.File)
+	}
+
+	got, err := decryptWinZipAES(t, zr.File[0], "s3cret")
+	if err != nil {
+		t.Fatalf("failed to decrypt: %v", err)
+	}
+	if got != content {
+		t.Error("decrypted content does not match")
+	}
+	if _, err := decryptWinZipAES(t, zr.File[0], "wrong"); err == nil {
+		t.Error("expected wrong password to be rejected")
+	}
+}
+
+func TestBuilder_ZipOversizedAttachments(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	mockDriver := &MockDriver{name: "mock", sendResult: &Result{Success: true}}
+	registry := NewRegistry()
+	registry.Register("mock", func(config map[string]any) (Driver, error) {
+		return mockDriver, nil
+	})
+
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+		Attachments: AttachmentPolicy{MaxSize: 1024},
+		Zip:         ZipConfig{Enabled: true, Filename: "export.zip"},
+	}
+
+	manager, err := NewManager(config, log, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 可压缩的大附件超过策略上限时打包后发送
+	builder := manager.New().
+		To("user@example.com").
+		Subject("Test").
+		Body("Hello").
+		Attach("export.csv", bytes.Repeat([]byte("0,0,0\n"), 1000)).
+		Attach("note.txt", []byte("hello"))
+	if _, err := builder.Send(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := mockDriver.sent
+	if sent == nil || len(sent.Attachments) != 2 || sent.Attachments[1].Filename != "export.zip" {
+		t.Fatalf("expected oversized attachment to be zipped, got %+v", sent)
+	}
+	if _, err := os.Stat(sent.Attachments[1].Path); !os.IsNotExist(err) {
+		t.Errorf("expected temp file to be removed after send, got %v", err)
+	}
+
+	// Builder 中的附件不受转换影响，可以再次发送
+	if msg := builder.Message(); len(msg.Attachments) != 2 || msg.Attachments[0].Filename != "export.csv" {
+		t.Errorf("expected builder attachments to be unchanged, got %+v", msg.Attachments)
+	}
+}
+
+func TestManager_Send_SameMessageTwice(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	mockDriver := &MockDriver{name: "mock", sendResult: &Result{Success: true}}
+	registry := NewRegistry()
+	registry.Register("mock", func(config map[string]any) (Driver, error) {
+		return mockDriver, nil
+	})
+
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+		Attachments: AttachmentPolicy{MaxSize: 1024},
+		Zip:         ZipConfig{Enabled: true, Filename: "export.zip"},
+	}
+
+	manager, err := NewManager(config, log, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		To:          []string{"user@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "export.csv", Content: bytes.Repeat([]byte("0,0,0\n"), 1000)}},
+	}
+
+	// 压缩包临时文件在发送后删除，第二次发送需要重新压缩原始附件
+	for i := 0; i < 2; i++ {
+		if _, err := manager.Send(context.Background(), "", msg); err != nil {
+			t.Fatalf("send %d: unexpected error: %v", i+1, err)
+		}
+		sent := mockDriver.sent
+		if len(sent.Attachments) != 1 || sent.Attachments[0].Filename != "export.zip" {
+			t.Fatalf("send %d: expected a single zip attachment, got %+v", i+1, sent.Attachments)
+		}
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "export.csv" || msg.Attachments[0].Path != "" {
+		t.Errorf("expected caller's attachments to be unchanged, got %+v", msg.Attachments)
+	}
+}
+
+func TestBuilder_ZipKeepsAttachmentPolicy(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	registry := NewRegistry()
+	registry.Register("mock", func(config map[string]any) (Driver, error) {
+		return &MockDriver{name: "mock", sendResult: &Result{Success: true}}, nil
+	})
+
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+		Attachments: AttachmentPolicy{DenyExtensions: []string{".exe"}},
+		Zip:         ZipConfig{Enabled: true, Threshold: 1},
+	}
+
+	manager, err := NewManager(config, log, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 被禁止的附件不能通过压缩绕过策略
+	_, err = manager.New().
+		To("user@example.com").
+		Subject("Test").
+		Body("Hello").
+		Attach("setup.exe", []byte("MZ")).
+		Send(context.Background())
+	if !errors.Is(err, ErrAttachmentRejected) {
+		t.Fatalf("expected ErrAttachmentRejected, got %v", err)
+	}
+}
+
+func TestNewManager_ZipWithoutThreshold(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+		Zip: ZipConfig{Enabled: true},
+	}
+	if _, err := NewManager(config, log, NewRegistry()); !errors.Is(err, ErrDriverConfig) {
+		t.Errorf("expected ErrDriverConfig, got %v", err)
+	}
+
+	// 未配置驱动时同样检查
+	config = &Config{Zip: ZipConfig{Enabled: true}}
+	if _, err := NewManager(config, log, NewRegistry()); !errors.Is(err, ErrDriverConfig) {
+		t.Errorf("expected ErrDriverConfig, got %v", err)
+	}
+}
@@ -1,6 +1,12 @@
 package email
 
-import "context"
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
 
 // Builder 邮件构建器（链式调用）
 type Builder struct {
@@ -8,6 +14,9 @@ type Builder struct {
 	driver  string
 	message *Message
 	err     error
+
+	// transformers 发送前执行的附件转换器
+	transformers []AttachmentTransformer
 }
 
 // Driver 指定驱动
@@ -37,6 +46,24 @@ func (b *Builder) FromName(name string) *Builder {
 	return b
 }
 
+// ReturnPath 设置信封发件人（接收退信的地址），不影响 From 头
+func (b *Builder) ReturnPath(addr string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.message.EnvelopeFrom = addr
+	return b
+}
+
+// VERP 启用 VERP，将收件人编码进信封发件人以便退信归因
+func (b *Builder) VERP() *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.message.VERP = true
+	return b
+}
+
 // To 添加收件人
 func (b *Builder) To(addrs ...string) *Builder {
 	if b.err != nil {
@@ -125,6 +152,40 @@ func (b *Builder) AttachWithType(filename string, content []byte, contentType st
 	return b
 }
 
+// AttachReader 添加流式附件，内容在发送时读取，不整体载入内存
+func (b *Builder) AttachReader(filename string, r io.Reader, contentType string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.message.Attachments = append(b.message.Attachments, Attachment{
+		Filename:    filename,
+		Reader:      r,
+		ContentType: contentType,
+	})
+	return b
+}
+
+// AttachFile 添加本地文件附件，文件在发送时打开并流式读取
+func (b *Builder) AttachFile(path string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		b.err = ErrInvalidMessage.Wrap(err).WithMsgf("附件文件不可用: %s", path)
+		return b
+	}
+	if info.IsDir() {
+		b.err = ErrInvalidMessage.WithMsgf("附件路径是目录: %s", path)
+		return b
+	}
+	b.message.Attachments = append(b.message.Attachments, Attachment{
+		Filename: filepath.Base(path),
+		Path:     path,
+	})
+	return b
+}
+
 // Embed 添加内联附件（用于邮件内图片）
 func (b *Builder) Embed(contentID, filename string, content []byte) *Builder {
 	if b.err != nil {
@@ -154,6 +215,64 @@ func (b *Builder) EmbedWithType(contentID, filename string, content []byte, cont
 	return b
 }
 
+// Tag 设置消息标签（用于厂商侧统计与事件归类）
+func (b *Builder) Tag(name, value string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if b.message.Tags == nil {
+		b.message.Tags = make(map[string]string)
+	}
+	b.message.Tags[name] = value
+	return b
+}
+
+// Category 添加消息分类
+func (b *Builder) Category(names ...string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.message.Categories = append(b.message.Categories, names...)
+	return b
+}
+
+// Importance 设置邮件重要性（驱动支持时生效，如 Graph）
+func (b *Builder) Importance(importance Importance) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.message.Importance = importance
+	return b
+}
+
+// Template 使用厂商侧模板（驱动支持时生效），data 为模板变量
+func (b *Builder) Template(id string, data map[string]any) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.message.TemplateID = id
+	b.message.TemplateData = data
+	return b
+}
+
+// SendAt 设置定时发送时间（SendGrid、Mandrill、Mailgun 支持，其余驱动返回 ErrInvalidMessage）
+func (b *Builder) SendAt(t time.Time) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.message.SendAt = t
+	return b
+}
+
+// Transform 添加附件转换器（如 ZipTransformer），在发送前按顺序执行
+func (b *Builder) Transform(transformers ...AttachmentTransformer) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.transformers = append(b.transformers, transformers...)
+	return b
+}
+
 // Header 设置自定义头
 func (b *Builder) Header(key, value string) *Builder {
 	if b.err != nil {
@@ -166,32 +285,61 @@ func (b *Builder) Header(key, value string) *Builder {
 	return b
 }
 
-// Send 发送邮件
-func (b *Builder) Send(ctx context.Context) (*Result, error) {
+// DSN 设置投递状态通知条件（作用于所有收件人）
+// notify: SUCCESS, FAILURE, DELAY 或 NEVER
+func (b *Builder) DSN(notify ...string) *Builder {
 	if b.err != nil {
-		return nil, b.err
+		return b
 	}
+	b.dsn().Notify = notify
+	return b
+}
 
-	// 应用默认发件人
-	if b.message.From == "" && b.manager.config.DefaultFrom != "" {
-		b.message.From = b.manager.config.DefaultFrom
+// DSNNotify 设置单个收件人的投递状态通知条件
+func (b *Builder) DSNNotify(addr string, notify ...string) *Builder {
+	if b.err != nil {
+		return b
 	}
-	if b.message.FromName == "" && b.manager.config.DefaultFromName != "" {
-		b.message.FromName = b.manager.config.DefaultFromName
+	dsn := b.dsn()
+	if dsn.RecipientNotify == nil {
+		dsn.RecipientNotify = make(map[string][]string)
 	}
+	dsn.RecipientNotify[addr] = notify
+	return b
+}
 
-	// 获取驱动
-	driverName := b.driver
-	if driverName == "" {
-		driverName = b.manager.config.Default
+// DSNReturn 设置退信内容: FULL 或 HDRS
+func (b *Builder) DSNReturn(ret string) *Builder {
+	if b.err != nil {
+		return b
 	}
+	b.dsn().Return = ret
+	return b
+}
 
-	driver, err := b.manager.GetDriver(driverName)
-	if err != nil {
-		return nil, err
+// DSNEnvelopeID 设置信封 ID（ENVID）
+func (b *Builder) DSNEnvelopeID(id string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	b.dsn().EnvelopeID = id
+	return b
+}
+
+// dsn 获取 DSN 选项，不存在时创建
+func (b *Builder) dsn() *DSNOptions {
+	if b.message.DSN == nil {
+		b.message.DSN = &DSNOptions{}
 	}
+	return b.message.DSN
+}
 
-	return driver.Send(ctx, b.message)
+// Send 发送邮件
+func (b *Builder) Send(ctx context.Context) (*Result, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.manager.send(ctx, b.driver, b.message, b.transformers)
 }
 
 // Message 获取构建的消息（用于调试）
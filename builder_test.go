@@ -2,6 +2,10 @@ package email
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/KOMKZ/go-yogan-framework/logger"
@@ -101,10 +105,13 @@ func TestBuilder_DefaultFrom(t *testing.T) {
 		t.Error("From should be empty before send")
 	}
 
-	// 发送后检查
+	// 默认值应用到驱动收到的消息，Builder 中的消息保持不变
 	_, _ = builder.Send(context.Background())
-	if msg.From != "default@example.com" {
-		t.Errorf("expected default from, got '%s'", msg.From)
+	if mockDriver.sent == nil || mockDriver.sent.From != "default@example.com" {
+		t.Errorf("expected default from, got %+v", mockDriver.sent)
+	}
+	if msg.From != "" {
+		t.Errorf("expected builder message to be unchanged, got '%s'", msg.From)
 	}
 }
 
@@ -255,3 +262,104 @@ func TestBuilder_SwitchDriver(t *testing.T) {
 		t.Errorf("expected from-driver2, got %s", result2.MessageID)
 	}
 }
+
+func TestBuilder_DSN(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	registry := NewRegistry()
+	registry.Register("mock", func(config map[string]any) (Driver, error) {
+		return &MockDriver{name: "mock", sendResult: &Result{Success: true}}, nil
+	})
+
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+	}
+
+	manager, err := NewManager(config, log, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := manager.New().
+		To("user@example.com", "legal@example.com").
+		Subject("Test").
+		Body("Hello").
+		DSN(DSNNotifyFailure).
+		DSNNotify("legal@example.com", DSNNotifySuccess, DSNNotifyFailure).
+		DSNReturn(DSNReturnFull).
+		DSNEnvelopeID("contract-42").
+		Message()
+
+	if msg.DSN == nil {
+		t.Fatal("expected DSN options")
+	}
+	if msg.DSN.Return != DSNReturnFull || msg.DSN.EnvelopeID != "contract-42" {
+		t.Errorf("unexpected DSN options: %+v", msg.DSN)
+	}
+	if got := msg.DSN.NotifyFor("legal@example.com"); len(got) != 2 {
+		t.Errorf("expected recipient notify override, got %v", got)
+	}
+}
+
+func TestBuilder_StreamingAttachments(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	registry := NewRegistry()
+	registry.Register("mock", func(config map[string]any) (Driver, error) {
+		return &MockDriver{name: "mock", sendResult: &Result{Success: true}}, nil
+	})
+
+	config := &Config{
+		Default: "mock",
+		Drivers: map[string]map[string]any{
+			"mock": {},
+		},
+	}
+
+	manager, err := NewManager(config, log, registry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(path, []byte("pdf"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	builder := manager.New().
+		To("user@example.com").
+		Subject("Test").
+		Body("Hello").
+		AttachReader("data.csv", strings.NewReader("a,b"), "text/csv").
+		AttachFile(path)
+
+	if err := builder.Error(); err != nil {
+		t.Fatalf("unexpected builder error: %v", err)
+	}
+	msg := builder.Message()
+	if len(msg.Attachments) != 2 {
+		t.Fatalf("expected 2 attachments, got %d", len(msg.Attachments))
+	}
+	if msg.Attachments[0].Reader == nil || msg.Attachments[0].ContentType != "text/csv" {
+		t.Errorf("unexpected reader attachment: %+v", msg.Attachments[0])
+	}
+	if msg.Attachments[1].Filename != "report.pdf" || msg.Attachments[1].Path != path {
+		t.Errorf("unexpected file attachment: %+v", msg.Attachments[1])
+	}
+
+	// 文件不存在时记录错误，Send 直接返回
+	builder = manager.New().
+		To("user@example.com").
+		Subject("Test").
+		Body("Hello").
+		AttachFile(filepath.Join(t.TempDir(), "missing.pdf"))
+	if !errors.Is(builder.Error(), ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage, got %v", builder.Error())
+	}
+	if _, err := builder.Send(context.Background()); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected Send to return builder error, got %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package email
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // ComponentName 组件名称（用于配置 key）
 const ComponentName = "email"
@@ -18,6 +22,12 @@ type Config struct {
 
 	// Drivers 驱动配置
 	Drivers map[string]map[string]any `mapstructure:"drivers"`
+
+	// Attachments 附件策略（可选），在 Builder.Send 与 Manager.Send 时检查
+	Attachments AttachmentPolicy `mapstructure:"attachments"`
+
+	// Zip 附件压缩（可选），在附件类型检查之后、大小检查之前执行
+	Zip ZipConfig `mapstructure:"zip"`
 }
 
 // Validate 验证配置
@@ -31,12 +41,83 @@ func (c *Config) Validate() error {
 	if _, ok := c.Drivers[c.Default]; !ok {
 		return fmt.Errorf("default driver '%s' is not configured", c.Default)
 	}
+	if err := c.Attachments.Validate(); err != nil {
+		return err
+	}
+	if c.Zip.Threshold < 0 {
+		return fmt.Errorf("zip threshold cannot be negative")
+	}
+	if _, err := c.zipThreshold(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// zipThreshold 附件压缩阈值，未配置时使用附件策略的单个附件上限
+// 启用压缩但两者均为 0 时没有可压缩的附件，视为配置错误
+func (c *Config) zipThreshold() (int64, error) {
+	threshold := c.Zip.Threshold
+	if threshold == 0 {
+		threshold = int64(c.Attachments.MaxSize)
+	}
+	if c.Zip.Enabled && threshold == 0 {
+		return 0, ErrDriverConfig.WithMsg("启用 zip 时需配置 zip.threshold 或 attachments.max_size")
+	}
+	return threshold, nil
+}
+
 // ApplyDefaults 应用默认值
 func (c *Config) ApplyDefaults() {
 	if c.Default == "" {
 		c.Default = DriverMandrill
 	}
 }
+
+// ByteSize 字节数，配置中可使用数字或带单位的字符串（如 10485760、"10MB"、"512KB"）
+type ByteSize int64
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，YAML 解码及启用文本解码钩子的配置加载器会调用
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	size, ok := parseByteSize(string(text))
+	if !ok {
+		return fmt.Errorf("invalid byte size: %q", text)
+	}
+	*s = ByteSize(size)
+	return nil
+}
+
+// parseByteSize 解析字节大小配置，支持数字与带单位的字符串（如 "10MB"、"512KB"）
+func parseByteSize(v any) (int64, bool) {
+	switch size := v.(type) {
+	case int:
+		return int64(size), true
+	case int64:
+		return size, true
+	case float64:
+		return int64(size), true
+	case string:
+		s := strings.ToUpper(strings.TrimSpace(size))
+		multiplier := int64(1)
+		for _, unit := range []struct {
+			suffix string
+			value  int64
+		}{
+			{"GB", 1 << 30},
+			{"MB", 1 << 20},
+			{"KB", 1 << 10},
+			{"B", 1},
+		} {
+			if strings.HasSuffix(s, unit.suffix) {
+				s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+				multiplier = unit.value
+				break
+			}
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return n * multiplier, true
+	}
+	return 0, false
+}
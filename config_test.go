@@ -78,3 +78,41 @@ func TestConfig_ApplyDefaults_NoOverwrite(t *testing.T) {
 		t.Errorf("expected default driver 'custom', got '%s'", config.Default)
 	}
 }
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input  any
+		want   int64
+		wantOK bool
+	}{
+		{1024, 1024, true},
+		{float64(2048), 2048, true},
+		{"512", 512, true},
+		{"512KB", 512 << 10, true},
+		{"10MB", 10 << 20, true},
+		{"1 gb", 1 << 30, true},
+		{"100B", 100, true},
+		{"abc", 0, false},
+		{nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseByteSize(tt.input)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseByteSize(%v) = %d, %v; want %d, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestByteSize_UnmarshalText(t *testing.T) {
+	var size ByteSize
+	if err := size.UnmarshalText([]byte("10MB")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size != 10<<20 {
+		t.Errorf("expected %d, got %d", 10<<20, size)
+	}
+	if err := size.UnmarshalText([]byte("ten")); err == nil {
+		t.Error("expected error for invalid size")
+	}
+}
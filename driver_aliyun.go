@@ -0,0 +1,313 @@
+package email
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverAliyun 阿里云邮件推送（DirectMail）驱动名称
+	DriverAliyun = "aliyun"
+
+	// AliyunDefaultRegion 默认区域
+	AliyunDefaultRegion = "cn-hangzhou"
+
+	// AliyunDefaultMaxMessageSize SingleSendMail 正文（HtmlBody、TextBody）大小上限（80KB）
+	AliyunDefaultMaxMessageSize = 80 << 10
+
+	// aliyunAPIVersion DirectMail API 版本
+	aliyunAPIVersion = "2015-11-23"
+)
+
+// AliyunConfig 阿里云邮件推送驱动配置
+type AliyunConfig struct {
+	// AccessKeyID 访问密钥 ID
+	AccessKeyID string `mapstructure:"access_key_id"`
+
+	// AccessKeySecret 访问密钥
+	AccessKeySecret string `mapstructure:"access_key_secret"`
+
+	// Region 区域（可选，默认 cn-hangzhou）
+	Region string `mapstructure:"region"`
+
+	// Endpoint API 地址（可选，默认按 region 生成）
+	Endpoint string `mapstructure:"endpoint"`
+
+	// AddressType 地址类型（可选，0 随机账号，1 发信地址，默认 1）
+	AddressType int `mapstructure:"address_type"`
+
+	// ClickTrace 是否开启数据跟踪（可选）
+	ClickTrace bool `mapstructure:"click_trace"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize HTML、纯文本正文各自的最大字节数（可选，默认 80KB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// AliyunDriver 阿里云邮件推送驱动（SingleSendMail）
+type AliyunDriver struct {
+	config *AliyunConfig
+	client *http.Client
+}
+
+// NewAliyunDriver 创建阿里云邮件推送驱动
+func NewAliyunDriver(config map[string]any) (Driver, error) {
+	cfg := &AliyunConfig{
+		Region:         AliyunDefaultRegion,
+		AddressType:    1,
+		Timeout:        30 * time.Second,
+		MaxMessageSize: AliyunDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if accessKeyID, ok := config["access_key_id"].(string); ok {
+		cfg.AccessKeyID = accessKeyID
+	}
+	if secret, ok := config["access_key_secret"].(string); ok {
+		cfg.AccessKeySecret = secret
+	}
+	if region, ok := config["region"].(string); ok && region != "" {
+		cfg.Region = region
+	}
+	if endpoint, ok := config["endpoint"].(string); ok && endpoint != "" {
+		cfg.Endpoint = strings.TrimRight(endpoint, "/")
+	}
+	if addressType, ok := config["address_type"].(int); ok {
+		cfg.AddressType = addressType
+	}
+	if clickTrace, ok := config["click_trace"].(bool); ok {
+		cfg.ClickTrace = clickTrace
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = aliyunEndpoint(cfg.Region)
+	}
+
+	driver := &AliyunDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// aliyunEndpoint 按区域生成 API 地址（杭州区域使用 dm.aliyuncs.com）
+func aliyunEndpoint(region string) string {
+	if region == AliyunDefaultRegion {
+		return "https://dm.aliyuncs.com"
+	}
+	return fmt.Sprintf("https://dm.%s.aliyuncs.com", region)
+}
+
+// Name 驱动名称
+func (d *AliyunDriver) Name() string {
+	return DriverAliyun
+}
+
+// Validate 验证配置
+func (d *AliyunDriver) Validate() error {
+	if d.config.AccessKeyID == "" || d.config.AccessKeySecret == "" {
+		return ErrDriverConfig.WithMsg("阿里云 AccessKeyID 与 AccessKeySecret 不能为空")
+	}
+	if d.config.AddressType != 0 && d.config.AddressType != 1 {
+		return ErrDriverConfig.WithMsgf("阿里云 AddressType 无效: %d", d.config.AddressType)
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("阿里云 MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+func (d *AliyunDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireImmediate(); err != nil {
+		return nil, err
+	}
+
+	// SingleSendMail 只支持收件人与正文
+	if len(msg.Cc) > 0 || len(msg.Bcc) > 0 {
+		return nil, ErrInvalidMessage.WithMsg("阿里云邮件推送不支持抄送与密送")
+	}
+	if len(msg.Attachments) > 0 {
+		return nil, ErrInvalidMessage.WithMsg("阿里云邮件推送不支持附件")
+	}
+	if len(msg.Categories) > 1 {
+		return nil, ErrInvalidMessage.WithMsg("阿里云邮件推送每封邮件只支持一个分类（TagName）")
+	}
+	if max := d.config.MaxMessageSize; max > 0 {
+		for _, body := range []string{msg.BodyHTML, msg.BodyText} {
+			if size := int64(len(body)); size > max {
+				return nil, ErrMessageTooLarge.WithMsgf("邮件正文 %d 字节超过限制 %d 字节", size, max)
+			}
+		}
+	}
+
+	params := d.buildParams(msg)
+	return d.doRequest(ctx, params)
+}
+
+// buildParams 构建 SingleSendMail 业务参数
+func (d *AliyunDriver) buildParams(msg *Message) url.Values {
+	params := url.Values{}
+	params.Set("Action", "SingleSendMail")
+	params.Set("AccountName", headerAddress(msg.From))
+	params.Set("AddressType", strconv.Itoa(d.config.AddressType))
+	params.Set("ToAddress", strings.Join(headerAddresses(msg.To), ","))
+	params.Set("Subject", msg.Subject)
+
+	if msg.FromName != "" {
+		params.Set("FromAlias", msg.FromName)
+	}
+	if msg.BodyHTML != "" {
+		params.Set("HtmlBody", msg.BodyHTML)
+	}
+	if msg.BodyText != "" {
+		params.Set("TextBody", msg.BodyText)
+	}
+
+	// 回信地址: ReplyToAddress 为开关，ReplyAddress 为具体地址
+	if msg.ReplyTo != "" {
+		params.Set("ReplyToAddress", "true")
+		params.Set("ReplyAddress", headerAddress(msg.ReplyTo))
+	} else {
+		params.Set("ReplyToAddress", "false")
+	}
+
+	// 邮件标签需在控制台预先创建，每封邮件只能使用一个（多个分类在 Send 中拒绝）
+	if len(msg.Categories) > 0 {
+		params.Set("TagName", msg.Categories[0])
+	}
+	if d.config.ClickTrace {
+		params.Set("ClickTrace", "1")
+	}
+	return params
+}
+
+// doRequest 添加公共参数并签名后发送请求
+func (d *AliyunDriver) doRequest(ctx context.Context, params url.Values) (*Result, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("生成签名随机数失败")
+	}
+
+	params.Set("Format", "JSON")
+	params.Set("Version", aliyunAPIVersion)
+	params.Set("AccessKeyId", d.config.AccessKeyID)
+	params.Set("SignatureMethod", "HMAC-SHA1")
+	params.Set("SignatureVersion", "1.0")
+	params.Set("SignatureNonce", hex.EncodeToString(nonce))
+	params.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	params.Set("RegionId", d.config.Region)
+	params.Set("Signature", aliyunRPCSignature(http.MethodPost, params, d.config.AccessKeySecret))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.config.Endpoint+"/", strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	// 解析响应
+	return d.parseResponse(resp, respBody)
+}
+
+// parseResponse 解析 SingleSendMail 响应
+func (d *AliyunDriver) parseResponse(resp *http.Response, body []byte) (*Result, error) {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var out struct {
+			EnvID     string `json:"EnvId"`
+			RequestID string `json:"RequestId"`
+		}
+		if err := json.Unmarshal(body, &out); err != nil {
+			return nil, ErrSendFailed.Wrap(err).WithMsg("解析响应失败")
+		}
+		messageID := out.EnvID
+		if messageID == "" {
+			messageID = out.RequestID
+		}
+		return &Result{
+			MessageID: messageID,
+			Status:    "sent",
+			Success:   true,
+		}, nil
+	}
+
+	aliErr := newAliyunError(resp, body)
+	return nil, aliyunErrorCode(aliErr).Wrap(aliErr).WithMsgf("阿里云 API 错误: %s - %s", aliErr.Code, aliErr.Message)
+}
+
+// aliyunRPCSignature 计算阿里云 RPC 风格签名（HMAC-SHA1）
+// 见 https://help.aliyun.com/document_detail/29442.html，参数编码规则与 SigV4 相同（RFC 3986）
+func aliyunRPCSignature(method string, params url.Values, secret string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k == "Signature" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, sigV4Escape(k)+"="+sigV4Escape(params.Get(k)))
+	}
+	stringToSign := method + "&" + sigV4Escape("/") + "&" + sigV4Escape(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func init() {
+	// 注册阿里云驱动到默认注册表
+	RegisterDriver(DriverAliyun, NewAliyunDriver)
+}
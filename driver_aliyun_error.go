@@ -0,0 +1,86 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KOMKZ/go-yogan-framework/errcode"
+)
+
+// AliyunError 阿里云 API 返回的错误，可通过 errors.As 获取
+type AliyunError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+
+	// Code 错误码（如 InvalidMailAddress.NotFound、Throttling.User）
+	Code string
+
+	// Message 错误描述
+	Message string
+
+	// RequestID 请求 ID，用于向阿里云排查问题
+	RequestID string
+}
+
+// Error 实现 error 接口
+func (e *AliyunError) Error() string {
+	return fmt.Sprintf("aliyun %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// newAliyunError 从错误响应解析 AliyunError
+func newAliyunError(resp *http.Response, body []byte) *AliyunError {
+	e := &AliyunError{StatusCode: resp.StatusCode}
+
+	var out struct {
+		Code      string `json:"Code"`
+		Message   string `json:"Message"`
+		RequestID string `json:"RequestId"`
+	}
+	_ = json.Unmarshal(body, &out)
+
+	e.Code = out.Code
+	if e.Code == "" {
+		e.Code = http.StatusText(resp.StatusCode)
+	}
+	e.Message = out.Message
+	if e.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	e.RequestID = out.RequestID
+	return e
+}
+
+// aliyunErrorCode 将阿里云错误码映射为组件错误码
+func aliyunErrorCode(e *AliyunError) *errcode.AppError {
+	switch e.Code {
+	case "InvalidMailAddress.NotFound", "InvalidMailAddressStatus.Malformed", "InvalidDomain.NotFound",
+		"InvalidAccountName.Malformed", "InvalidDomainNotFound":
+		// 发信地址或域名未在控制台配置
+		return ErrDriverConfig
+	case "SignatureDoesNotMatch", "IncompleteSignature", "Forbidden.RAM", "Forbidden.AccessKeyDisabled":
+		return ErrAuthFailed
+	case "InvalidSendMail.Spam", "InvalidBody.Spam":
+		return ErrSendFailed
+	}
+
+	switch {
+	case strings.HasPrefix(e.Code, "Throttling"):
+		return ErrRateLimited
+	case strings.HasPrefix(e.Code, "InvalidAccessKeyId"):
+		return ErrAuthFailed
+	case strings.HasPrefix(e.Code, "InvalidToAddress"), strings.HasPrefix(e.Code, "InvalidReceiver"):
+		return ErrInvalidRecipient
+	case strings.HasPrefix(e.Code, "InvalidBody"), strings.HasPrefix(e.Code, "InvalidSubject"),
+		strings.HasPrefix(e.Code, "InvalidFromALias"), strings.HasPrefix(e.Code, "InvalidTagName"),
+		strings.HasPrefix(e.Code, "InvalidReplyAddress"), strings.HasPrefix(e.Code, "MissingParameter"):
+		return ErrInvalidMessage
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuthFailed
+	default:
+		return ErrSendFailed
+	}
+}
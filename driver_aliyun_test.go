@@ -0,0 +1,241 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNewAliyunDriver(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]any
+		endpoint string
+		wantErr  bool
+	}{
+		{
+			name:     "default region",
+			config:   map[string]any{"access_key_id": "id", "access_key_secret": "secret"},
+			endpoint: "https://dm.aliyuncs.com",
+		},
+		{
+			name:     "other region",
+			config:   map[string]any{"access_key_id": "id", "access_key_secret": "secret", "region": "ap-southeast-1"},
+			endpoint: "https://dm.ap-southeast-1.aliyuncs.com",
+		},
+		{
+			name:     "endpoint override",
+			config:   map[string]any{"access_key_id": "id", "access_key_secret": "secret", "endpoint": "http://127.0.0.1:8080/"},
+			endpoint: "http://127.0.0.1:8080",
+		},
+		{
+			name:    "missing credentials",
+			config:  map[string]any{"access_key_id": "id"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid address type",
+			config:  map[string]any{"access_key_id": "id", "access_key_secret": "secret", "address_type": 2},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := NewAliyunDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAliyunDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && driver.(*AliyunDriver).config.Endpoint != tt.endpoint {
+				t.Errorf("unexpected endpoint: %s", driver.(*AliyunDriver).config.Endpoint)
+			}
+		})
+	}
+}
+
+func TestAliyunRPCSignature(t *testing.T) {
+	// 阿里云签名文档中的示例
+	params := url.Values{}
+	params.Set("AccessKeyId", "testid")
+	params.Set("Action", "DescribeRegions")
+	params.Set("Format", "XML")
+	params.Set("SignatureMethod", "HMAC-SHA1")
+	params.Set("SignatureNonce", "3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf")
+	params.Set("SignatureVersion", "1.0")
+	params.Set("Timestamp", "2016-02-23T12:46:24Z")
+	params.Set("Version", "2014-05-26")
+
+	if got := aliyunRPCSignature(http.MethodGet, params, "testsecret"); got != "OLeaidS1JvxuMvnyHOwuJ+uX5qY=" {
+		t.Errorf("unexpected signature: %s", got)
+	}
+}
+
+// newTestAliyunDriver 创建指向本地服务器的阿里云驱动
+func newTestAliyunDriver(t *testing.T, handler http.HandlerFunc) Driver {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	driver, err := NewAliyunDriver(map[string]any{
+		"access_key_id":     "id",
+		"access_key_secret": "secret",
+		"endpoint":          server.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver
+}
+
+func TestAliyunDriver_Send_Success(t *testing.T) {
+	var form url.Values
+	driver := newTestAliyunDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Write([]byte(`{"EnvId":"env-1","RequestId":"req-1"}`))
+	})
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:       "noreply@mail.example.com",
+		FromName:   "Example",
+		To:         []string{"a@example.com", "b@example.com"},
+		ReplyTo:    "support@example.com",
+		Subject:    "Test",
+		BodyHTML:   "<p>Hello</p>",
+		BodyText:   "Hello",
+		Categories: []string{"welcome"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessageID != "env-1" || !result.Success {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	want := map[string]string{
+		"Action":         "SingleSendMail",
+		"AccountName":    "noreply@mail.example.com",
+		"AddressType":    "1",
+		"ToAddress":      "a@example.com,b@example.com",
+		"FromAlias":      "Example",
+		"HtmlBody":       "<p>Hello</p>",
+		"TextBody":       "Hello",
+		"ReplyToAddress": "true",
+		"ReplyAddress":   "support@example.com",
+		"TagName":        "welcome",
+		"AccessKeyId":    "id",
+		"RegionId":       AliyunDefaultRegion,
+	}
+	for k, v := range want {
+		if got := form.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if sig := form.Get("Signature"); sig != aliyunRPCSignature(http.MethodPost, form, "secret") {
+		t.Errorf("signature mismatch: %s", sig)
+	}
+}
+
+func TestAliyunDriver_Send_Unsupported(t *testing.T) {
+	driver := newTestAliyunDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	tests := []struct {
+		name string
+		msg  *Message
+	}{
+		{"cc", &Message{From: "a@example.com", To: []string{"b@example.com"}, Cc: []string{"c@example.com"}, Subject: "s", BodyText: "t"}},
+		{"attachments", &Message{From: "a@example.com", To: []string{"b@example.com"}, Subject: "s", BodyText: "t",
+			Attachments: []Attachment{{Filename: "a.txt", Content: []byte("a")}}}},
+		{"categories", &Message{From: "a@example.com", To: []string{"b@example.com"}, Subject: "s", BodyText: "t",
+			Categories: []string{"welcome", "newsletter"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := driver.Send(context.Background(), tt.msg); !errors.Is(err, ErrInvalidMessage) {
+				t.Errorf("expected ErrInvalidMessage, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAliyunDriver_Send_TooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	}))
+	t.Cleanup(server.Close)
+
+	driver, err := NewAliyunDriver(map[string]any{
+		"access_key_id":     "id",
+		"access_key_secret": "secret",
+		"endpoint":          server.URL,
+		"max_message_size":  "1KB",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	if cfg := driver.(*AliyunDriver).config; cfg.MaxMessageSize != 1<<10 {
+		t.Errorf("expected max message size 1KB, got %d", cfg.MaxMessageSize)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "a@example.com",
+		To:       []string{"b@example.com"},
+		Subject:  "s",
+		BodyHTML: "<p>" + strings.Repeat("a", 2<<10) + "</p>",
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+}
+
+func TestAliyunDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		code    string
+		wantErr error
+	}{
+		{"sender not found", http.StatusBadRequest, "InvalidMailAddress.NotFound", ErrDriverConfig},
+		{"bad recipient", http.StatusBadRequest, "InvalidToAddress", ErrInvalidRecipient},
+		{"bad body", http.StatusBadRequest, "InvalidBody", ErrInvalidMessage},
+		{"throttled", http.StatusBadRequest, "Throttling.User", ErrRateLimited},
+		{"bad key", http.StatusNotFound, "InvalidAccessKeyId.NotFound", ErrAuthFailed},
+		{"bad signature", http.StatusBadRequest, "SignatureDoesNotMatch", ErrAuthFailed},
+		{"server error", http.StatusInternalServerError, "InternalError", ErrSendFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := newTestAliyunDriver(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"RequestId":"req-1","Code":"` + tt.code + `","Message":"error"}`))
+			})
+
+			_, err := driver.Send(context.Background(), &Message{
+				From:     "noreply@mail.example.com",
+				To:       []string{"a@example.com"},
+				Subject:  "Test",
+				BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var aliErr *AliyunError
+			if !errors.As(err, &aliErr) || aliErr.Code != tt.code || aliErr.RequestID != "req-1" {
+				t.Errorf("expected AliyunError %s, got %v", tt.code, aliErr)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistry_Aliyun(t *testing.T) {
+	if !DefaultRegistry.Has(DriverAliyun) {
+		t.Error("expected aliyun driver to be registered")
+	}
+}
@@ -0,0 +1,345 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverGmail Gmail API 驱动名称
+	DriverGmail = "gmail"
+
+	// GmailDefaultBaseURL Gmail API 默认地址
+	GmailDefaultBaseURL = "https://gmail.googleapis.com"
+
+	// GmailDefaultTokenURL Google OAuth 2.0 默认令牌地址
+	GmailDefaultTokenURL = "https://oauth2.googleapis.com/token"
+
+	// GmailDefaultScope 默认授权范围（仅发送）
+	GmailDefaultScope = "https://www.googleapis.com/auth/gmail.send"
+
+	// GmailDefaultMaxMessageSize Gmail API 单封邮件大小上限（35MB）
+	GmailDefaultMaxMessageSize = 35 << 20
+)
+
+// GmailConfig Gmail API 驱动配置
+// 使用服务账号（CredentialsFile/CredentialsJSON，可配合 Subject 进行全域委派）或刷新令牌（ClientID/ClientSecret/RefreshToken）二选一
+type GmailConfig struct {
+	// CredentialsFile 服务账号密钥文件路径
+	CredentialsFile string `mapstructure:"credentials_file"`
+
+	// CredentialsJSON 服务账号密钥内容（JSON）
+	CredentialsJSON string `mapstructure:"credentials_json"`
+
+	// Subject 全域委派时代为发信的 Workspace 用户
+	Subject string `mapstructure:"subject"`
+
+	// ClientID OAuth 客户端 ID（刷新令牌方式）
+	ClientID string `mapstructure:"client_id"`
+
+	// ClientSecret OAuth 客户端密钥（刷新令牌方式）
+	ClientSecret string `mapstructure:"client_secret"`
+
+	// RefreshToken 刷新令牌
+	RefreshToken string `mapstructure:"refresh_token"`
+
+	// UserID 发信用户（可选，默认 me，即令牌对应的用户）
+	UserID string `mapstructure:"user_id"`
+
+	// TokenURL 令牌地址（可选，默认使用密钥中的 token_uri 或 https://oauth2.googleapis.com/token）
+	TokenURL string `mapstructure:"token_url"`
+
+	// BaseURL Gmail API 地址（可选，默认 https://gmail.googleapis.com）
+	BaseURL string `mapstructure:"base_url"`
+
+	// Scope 授权范围（可选，默认 https://www.googleapis.com/auth/gmail.send）
+	Scope string `mapstructure:"scope"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 邮件大小上限（可选，默认 35MB，Gmail API 上限）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// GmailDriver Gmail API 邮件驱动（users.messages.send）
+type GmailDriver struct {
+	config  *GmailConfig
+	client  *http.Client
+	account *googleServiceAccount
+
+	tokens oauthTokenCache
+}
+
+// NewGmailDriver 创建 Gmail API 驱动
+func NewGmailDriver(config map[string]any) (Driver, error) {
+	cfg := &GmailConfig{
+		UserID:         "me",
+		BaseURL:        GmailDefaultBaseURL,
+		Scope:          GmailDefaultScope,
+		Timeout:        30 * time.Second,
+		MaxMessageSize: GmailDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if file, ok := config["credentials_file"].(string); ok {
+		cfg.CredentialsFile = file
+	}
+	if credentials, ok := config["credentials_json"].(string); ok {
+		cfg.CredentialsJSON = credentials
+	}
+	if subject, ok := config["subject"].(string); ok {
+		cfg.Subject = subject
+	}
+	if clientID, ok := config["client_id"].(string); ok {
+		cfg.ClientID = clientID
+	}
+	if secret, ok := config["client_secret"].(string); ok {
+		cfg.ClientSecret = secret
+	}
+	if refreshToken, ok := config["refresh_token"].(string); ok {
+		cfg.RefreshToken = refreshToken
+	}
+	if userID, ok := config["user_id"].(string); ok && userID != "" {
+		cfg.UserID = userID
+	}
+	if tokenURL, ok := config["token_url"].(string); ok && tokenURL != "" {
+		cfg.TokenURL = tokenURL
+	}
+	if baseURL, ok := config["base_url"].(string); ok && baseURL != "" {
+		cfg.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if scope, ok := config["scope"].(string); ok && scope != "" {
+		cfg.Scope = scope
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	driver := &GmailDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 加载服务账号密钥
+	if cfg.CredentialsFile != "" || cfg.CredentialsJSON != "" {
+		data := []byte(cfg.CredentialsJSON)
+		if cfg.CredentialsFile != "" {
+			var err error
+			if data, err = os.ReadFile(cfg.CredentialsFile); err != nil {
+				return nil, ErrDriverConfig.Wrap(err).WithMsg("读取 Gmail 服务账号密钥失败")
+			}
+		}
+		account, err := parseGoogleServiceAccount(data)
+		if err != nil {
+			return nil, err
+		}
+		driver.account = account
+		if cfg.TokenURL == "" {
+			cfg.TokenURL = account.TokenURI
+		}
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = GmailDefaultTokenURL
+	}
+
+	return driver, nil
+}
+
+// Name 驱动名称
+func (d *GmailDriver) Name() string {
+	return DriverGmail
+}
+
+// Validate 验证配置
+func (d *GmailDriver) Validate() error {
+	serviceAccount := d.config.CredentialsFile != "" || d.config.CredentialsJSON != ""
+	refreshToken := d.config.RefreshToken != ""
+
+	switch {
+	case serviceAccount && refreshToken:
+		return ErrDriverConfig.WithMsg("Gmail 服务账号与刷新令牌只能配置一种")
+	case d.config.CredentialsFile != "" && d.config.CredentialsJSON != "":
+		return ErrDriverConfig.WithMsg("Gmail credentials_file 与 credentials_json 只能配置一个")
+	case refreshToken:
+		if d.config.ClientID == "" || d.config.ClientSecret == "" {
+			return ErrDriverConfig.WithMsg("Gmail 刷新令牌方式需要 ClientID 与 ClientSecret")
+		}
+	case !serviceAccount:
+		return ErrDriverConfig.WithMsg("Gmail 需要配置服务账号密钥或刷新令牌")
+	}
+	return nil
+}
+
+// Send 发送邮件
+// 使用组件构建的完整 MIME 邮件（与 SMTP 驱动一致），以 base64url 编码作为 raw 提交
+func (d *GmailDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireImmediate(); err != nil {
+		return nil, err
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+
+	payload, err := d.buildPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.doRequest(ctx, payload)
+}
+
+// buildPayload 构建 {"raw": "..."} 请求体
+// base64url 字符无需 JSON 转义，直接流式编码写入，避免再复制一份邮件内容
+func (d *GmailDriver) buildPayload(msg *Message) ([]byte, error) {
+	body, err := newSMTPBody(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gmail 从 Bcc 头读取密送收件人，发送时会移除该头（仍计入邮件大小）
+	var bcc string
+	if len(msg.Bcc) > 0 {
+		bcc = fmt.Sprintf("Bcc: %s\r\n", strings.Join(headerAddresses(msg.Bcc), ", "))
+	}
+	if max, size := d.config.MaxMessageSize, int64(len(bcc))+body.Size(); max > 0 && size > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", size, max)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"raw":"`)
+	enc := base64.NewEncoder(base64.URLEncoding, &buf)
+
+	if _, err := io.WriteString(enc, bcc); err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+	n, err := body.WriteTo(enc)
+	if err != nil {
+		if isAppError(err) {
+			return nil, err
+		}
+		return nil, ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+	// 流式附件的实际大小在写入后才能确定
+	if max, size := d.config.MaxMessageSize, int64(len(bcc))+n; max > 0 && size > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", size, max)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+	buf.WriteString(`"}`)
+
+	return buf.Bytes(), nil
+}
+
+// doRequest 调用 users.messages.send
+func (d *GmailDriver) doRequest(ctx context.Context, payload []byte) (*Result, error) {
+	token, err := d.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/gmail/v1/users/%s/messages/send", d.config.BaseURL, url.PathEscape(d.config.UserID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	return d.parseResponse(resp)
+}
+
+// parseResponse 解析响应
+func (d *GmailDriver) parseResponse(resp *http.Response) (*Result, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		gmailErr := newGmailError(resp.StatusCode, body)
+		if resp.StatusCode == http.StatusUnauthorized {
+			// 令牌可能已被吊销，下次发送时重新获取
+			d.tokens.reset()
+		}
+		return nil, gmailErrorCode(gmailErr).Wrap(gmailErr).WithMsgf("Gmail API 错误: %s - %s", gmailErr.Reason, gmailErr.Message)
+	}
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("解析响应失败")
+	}
+
+	return &Result{
+		MessageID: out.ID,
+		Status:    "sent",
+		Success:   true,
+	}, nil
+}
+
+// accessToken 获取访问令牌，过期前复用缓存
+func (d *GmailDriver) accessToken(ctx context.Context) (string, error) {
+	return d.tokens.get(ctx, func(ctx context.Context) (string, time.Duration, error) {
+		form := url.Values{}
+		if d.account != nil {
+			// 服务账号 JWT 授权（RFC 7523）
+			assertion, err := d.account.assertion(d.config.Scope, d.config.Subject, d.config.TokenURL, time.Now())
+			if err != nil {
+				return "", 0, err
+			}
+			form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+			form.Set("assertion", assertion)
+		} else {
+			form.Set("grant_type", "refresh_token")
+			form.Set("client_id", d.config.ClientID)
+			form.Set("client_secret", d.config.ClientSecret)
+			form.Set("refresh_token", d.config.RefreshToken)
+		}
+		return requestOAuthToken(ctx, d.client, d.config.TokenURL, form)
+	})
+}
+
+func init() {
+	// 注册 Gmail 驱动到默认注册表
+	RegisterDriver(DriverGmail, NewGmailDriver)
+}
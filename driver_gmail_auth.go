@@ -0,0 +1,89 @@
+package email
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+)
+
+// googleServiceAccount 服务账号密钥（从 Google Cloud 控制台下载的 JSON）
+type googleServiceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+
+	key *rsa.PrivateKey
+}
+
+// parseGoogleServiceAccount 解析服务账号密钥
+func parseGoogleServiceAccount(data []byte) (*googleServiceAccount, error) {
+	account := &googleServiceAccount{}
+	if err := json.Unmarshal(data, account); err != nil {
+		return nil, ErrDriverConfig.Wrap(err).WithMsg("解析 Gmail 服务账号密钥失败")
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, ErrDriverConfig.WithMsg("Gmail 服务账号密钥缺少 client_email 或 private_key")
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, ErrDriverConfig.WithMsg("Gmail 服务账号私钥不是 PEM 格式")
+	}
+
+	// 服务账号私钥为 PKCS#8，兼容 PKCS#1
+	var key any
+	var err error
+	if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, ErrDriverConfig.Wrap(err).WithMsg("解析 Gmail 服务账号私钥失败")
+		}
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrDriverConfig.WithMsg("Gmail 服务账号私钥必须为 RSA 密钥")
+	}
+	account.key = rsaKey
+	return account, nil
+}
+
+// assertion 生成 RS256 签名的 JWT 断言（RFC 7523），subject 不为空时代为该用户申请令牌（全域委派）
+func (a *googleServiceAccount) assertion(scope, subject, audience string, now time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if a.PrivateKeyID != "" {
+		header["kid"] = a.PrivateKeyID
+	}
+	claims := map[string]any{
+		"iss":   a.ClientEmail,
+		"scope": scope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	if subject != "" {
+		claims["sub"] = subject
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", ErrAuthFailed.Wrap(err).WithMsg("构建 JWT 失败")
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", ErrAuthFailed.Wrap(err).WithMsg("构建 JWT 失败")
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", ErrAuthFailed.Wrap(err).WithMsg("签名 JWT 失败")
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
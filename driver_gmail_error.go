@@ -0,0 +1,92 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KOMKZ/go-yogan-framework/errcode"
+)
+
+// GmailError Gmail API 返回的错误，可通过 errors.As 获取
+type GmailError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+
+	// Status 错误状态（如 INVALID_ARGUMENT、FAILED_PRECONDITION）
+	Status string
+
+	// Reason 错误原因（如 invalidArgument、rateLimitExceeded）
+	Reason string
+
+	// Message 错误描述
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *GmailError) Error() string {
+	return fmt.Sprintf("gmail %d %s: %s", e.StatusCode, e.Reason, e.Message)
+}
+
+// newGmailError 从错误响应解析 GmailError
+func newGmailError(statusCode int, body []byte) *GmailError {
+	e := &GmailError{StatusCode: statusCode}
+
+	var out struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Errors  []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &out)
+
+	e.Status = out.Error.Status
+	if len(out.Error.Errors) > 0 {
+		e.Reason = out.Error.Errors[0].Reason
+	}
+	e.Message = out.Error.Message
+	if e.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// gmailErrorCode 将 Gmail 错误映射为组件错误码
+func gmailErrorCode(e *GmailError) *errcode.AppError {
+	switch e.Reason {
+	case "rateLimitExceeded", "userRateLimitExceeded", "dailyLimitExceeded", "quotaExceeded":
+		// 配额错误以 403 或 429 返回
+		return ErrRateLimited
+	case "failedPrecondition":
+		// 邮箱未启用 Gmail 或委派配置有误
+		return ErrDriverConfig
+	}
+
+	// 收件人地址无效时返回 "Invalid To header" 等
+	if e.StatusCode == http.StatusBadRequest &&
+		(strings.HasPrefix(e.Message, "Invalid To header") ||
+			strings.HasPrefix(e.Message, "Invalid Cc header") ||
+			strings.HasPrefix(e.Message, "Invalid Bcc header") ||
+			strings.HasPrefix(e.Message, "Recipient address required")) {
+		return ErrInvalidRecipient
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthFailed
+	case http.StatusNotFound:
+		return ErrDriverConfig
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusRequestEntityTooLarge:
+		return ErrMessageTooLarge
+	case http.StatusBadRequest:
+		return ErrInvalidMessage
+	default:
+		return ErrSendFailed
+	}
+}
@@ -0,0 +1,449 @@
+package email
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testGmailKey 测试用服务账号私钥
+var testGmailKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+// testGmailCredentials 生成服务账号密钥 JSON
+func testGmailCredentials(t *testing.T, tokenURI string) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(testGmailKey())
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "mailer@project.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      tokenURI,
+	})
+	return string(data)
+}
+
+func TestNewGmailDriver(t *testing.T) {
+	credentials := testGmailCredentials(t, "")
+	file := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(file, []byte(testGmailCredentials(t, "http://127.0.0.1/token")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		config   map[string]any
+		tokenURL string
+		wantErr  bool
+	}{
+		{
+			name:     "service account json",
+			config:   map[string]any{"credentials_json": credentials, "subject": "user@example.com"},
+			tokenURL: GmailDefaultTokenURL,
+		},
+		{
+			name:     "service account file uses token_uri",
+			config:   map[string]any{"credentials_file": file},
+			tokenURL: "http://127.0.0.1/token",
+		},
+		{
+			name:     "refresh token",
+			config:   map[string]any{"client_id": "id", "client_secret": "secret", "refresh_token": "refresh"},
+			tokenURL: GmailDefaultTokenURL,
+		},
+		{
+			name:    "no credentials",
+			config:  map[string]any{},
+			wantErr: true,
+		},
+		{
+			name:    "refresh token without client",
+			config:  map[string]any{"refresh_token": "refresh"},
+			wantErr: true,
+		},
+		{
+			name:    "both modes",
+			config:  map[string]any{"credentials_json": credentials, "client_id": "id", "client_secret": "secret", "refresh_token": "refresh"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			config:  map[string]any{"credentials_json": `{"client_email":"a@b","private_key":"not pem"}`},
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			config:  map[string]any{"credentials_file": filepath.Join(t.TempDir(), "missing.json")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := NewGmailDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGmailDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && driver.(*GmailDriver).config.TokenURL != tt.tokenURL {
+				t.Errorf("unexpected token url: %s", driver.(*GmailDriver).config.TokenURL)
+			}
+		})
+	}
+}
+
+// fakeGmail 本地模拟的 Google 令牌与 Gmail 接口
+type fakeGmail struct {
+	mu         sync.Mutex
+	tokenCalls int
+	tokenForm  url.Values
+	path       string
+	auth       string
+	raw        string
+	status     int
+	response   string
+}
+
+func (f *fakeGmail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		f.tokenCalls++
+		r.ParseForm()
+		f.tokenForm = r.PostForm
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3599,"token_type":"Bearer"}`, f.tokenCalls)
+		return
+	}
+
+	f.path = r.URL.Path
+	f.auth = r.Header.Get("Authorization")
+	var payload struct {
+		Raw string `json:"raw"`
+	}
+	body, _ := io.ReadAll(r.Body)
+	json.Unmarshal(body, &payload)
+	f.raw = payload.Raw
+
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		w.Write([]byte(f.response))
+		return
+	}
+	w.Write([]byte(`{"id":"18c2f0a1b2c3d4e5","threadId":"18c2f0a1b2c3d4e5","labelIds":["SENT"]}`))
+}
+
+// newTestGmailDriver 创建指向本地服务器的 Gmail 驱动
+func newTestGmailDriver(t *testing.T, fake *fakeGmail, config map[string]any) *GmailDriver {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg := map[string]any{
+		"token_url": server.URL + "/token",
+		"base_url":  server.URL,
+	}
+	if _, ok := config["credentials_json"]; !ok {
+		cfg["client_id"] = "id"
+		cfg["client_secret"] = "secret"
+		cfg["refresh_token"] = "refresh"
+	}
+	for k, v := range config {
+		cfg[k] = v
+	}
+	driver, err := NewGmailDriver(cfg)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver.(*GmailDriver)
+}
+
+func TestGmailDriver_Send_ServiceAccount(t *testing.T) {
+	fake := &fakeGmail{}
+	driver := newTestGmailDriver(t, fake, map[string]any{
+		"credentials_json": testGmailCredentials(t, ""),
+		"subject":          "user@example.com",
+	})
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:        "user@example.com",
+		To:          []string{"a@example.com"},
+		Cc:          []string{"cc@example.com"},
+		Bcc:         []string{"bcc1@example.com", "bcc2@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "report.pdf", Content: []byte("%PDF-1.4")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessageID != "18c2f0a1b2c3d4e5" || !result.Success || result.Status != "sent" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if fake.tokenForm.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		t.Errorf("unexpected grant_type: %s", fake.tokenForm.Get("grant_type"))
+	}
+	parts := strings.Split(fake.tokenForm.Get("assertion"), ".")
+	if len(parts) != 3 {
+		t.Fatalf("invalid assertion: %s", fake.tokenForm.Get("assertion"))
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&testGmailKey().PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("invalid assertion signature: %v", err)
+	}
+	var header, claims map[string]any
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	json.Unmarshal(headerJSON, &header)
+	json.Unmarshal(claimsJSON, &claims)
+	if header["alg"] != "RS256" || header["kid"] != "key-1" {
+		t.Errorf("unexpected header: %v", header)
+	}
+	if claims["iss"] != "mailer@project.iam.gserviceaccount.com" || claims["sub"] != "user@example.com" ||
+		claims["scope"] != GmailDefaultScope || claims["aud"] != driver.config.TokenURL {
+		t.Errorf("unexpected claims: %v", claims)
+	}
+	if exp, iat := claims["exp"].(float64), claims["iat"].(float64); exp-iat != 3600 {
+		t.Errorf("unexpected expiry: iat=%v exp=%v", iat, exp)
+	}
+
+	if fake.path != "/gmail/v1/users/me/messages/send" || fake.auth != "Bearer token-1" {
+		t.Errorf("unexpected request: %s %s", fake.path, fake.auth)
+	}
+	raw, err := base64.URLEncoding.DecodeString(fake.raw)
+	if err != nil {
+		t.Fatalf("raw is not base64url: %v", err)
+	}
+	mime := string(raw)
+	for _, want := range []string{
+		"Bcc: bcc1@example.com, bcc2@example.com\r\n",
+		"From: user@example.com\r\n",
+		"To: a@example.com\r\n",
+		"Cc: cc@example.com\r\n",
+		"Subject: Test\r\n",
+		`filename="report.pdf"`,
+	} {
+		if !strings.Contains(mime, want) {
+			t.Errorf("raw message missing %q:\n%s", want, mime)
+		}
+	}
+}
+
+func TestGmailDriver_Send_RefreshToken(t *testing.T) {
+	fake := &fakeGmail{}
+	driver := newTestGmailDriver(t, fake, map[string]any{"user_id": "user@example.com"})
+	msg := &Message{From: "user@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyHTML: "<p>Hello</p>"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := driver.Send(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if fake.tokenCalls != 1 {
+		t.Errorf("expected token to be cached, got %d token requests", fake.tokenCalls)
+	}
+	form := fake.tokenForm
+	if form.Get("grant_type") != "refresh_token" || form.Get("refresh_token") != "refresh" ||
+		form.Get("client_id") != "id" || form.Get("client_secret") != "secret" {
+		t.Errorf("unexpected token request: %v", form)
+	}
+	if fake.path != "/gmail/v1/users/user@example.com/messages/send" {
+		t.Errorf("unexpected path: %s", fake.path)
+	}
+	raw, _ := base64.URLEncoding.DecodeString(fake.raw)
+	if strings.Contains(string(raw), "Bcc:") {
+		t.Error("unexpected Bcc header")
+	}
+}
+
+func TestGmailDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		wantErr  error
+	}{
+		{
+			name:     "invalid recipient",
+			status:   http.StatusBadRequest,
+			response: `{"error":{"code":400,"message":"Invalid To header","status":"INVALID_ARGUMENT","errors":[{"reason":"invalidArgument"}]}}`,
+			wantErr:  ErrInvalidRecipient,
+		},
+		{
+			name:     "invalid argument",
+			status:   http.StatusBadRequest,
+			response: `{"error":{"code":400,"message":"'raw' RFC822 payload message string or uploading message via /upload/* URL required","status":"INVALID_ARGUMENT","errors":[{"reason":"invalidArgument"}]}}`,
+			wantErr:  ErrInvalidMessage,
+		},
+		{
+			name:     "precondition failed",
+			status:   http.StatusBadRequest,
+			response: `{"error":{"code":400,"message":"Precondition check failed.","status":"FAILED_PRECONDITION","errors":[{"reason":"failedPrecondition"}]}}`,
+			wantErr:  ErrDriverConfig,
+		},
+		{
+			name:     "insufficient permissions",
+			status:   http.StatusForbidden,
+			response: `{"error":{"code":403,"message":"Request had insufficient authentication scopes.","status":"PERMISSION_DENIED","errors":[{"reason":"insufficientPermissions"}]}}`,
+			wantErr:  ErrAuthFailed,
+		},
+		{
+			name:     "user rate limit",
+			status:   http.StatusForbidden,
+			response: `{"error":{"code":403,"message":"User-rate limit exceeded","errors":[{"reason":"userRateLimitExceeded"}]}}`,
+			wantErr:  ErrRateLimited,
+		},
+		{
+			name:     "too many requests",
+			status:   http.StatusTooManyRequests,
+			response: `{"error":{"code":429,"message":"Too many concurrent requests for user","status":"RESOURCE_EXHAUSTED","errors":[{"reason":"rateLimitExceeded"}]}}`,
+			wantErr:  ErrRateLimited,
+		},
+		{
+			name:     "server error",
+			status:   http.StatusInternalServerError,
+			response: `{"error":{"code":500,"message":"Backend Error","errors":[{"reason":"backendError"}]}}`,
+			wantErr:  ErrSendFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGmail{status: tt.status, response: tt.response}
+			driver := newTestGmailDriver(t, fake, nil)
+
+			_, err := driver.Send(context.Background(), &Message{
+				From: "user@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var gmailErr *GmailError
+			if !errors.As(err, &gmailErr) || gmailErr.StatusCode != tt.status {
+				t.Errorf("expected GmailError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGmailDriver_Send_Unauthorized(t *testing.T) {
+	fake := &fakeGmail{
+		status:   http.StatusUnauthorized,
+		response: `{"error":{"code":401,"message":"Invalid Credentials","status":"UNAUTHENTICATED","errors":[{"reason":"authError"}]}}`,
+	}
+	driver := newTestGmailDriver(t, fake, nil)
+	msg := &Message{From: "user@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := driver.Send(context.Background(), msg); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("expected ErrAuthFailed, got %v", err)
+		}
+	}
+	// 401 后清除缓存的令牌
+	if fake.tokenCalls != 2 {
+		t.Errorf("expected token to be refreshed after 401, got %d token requests", fake.tokenCalls)
+	}
+}
+
+func TestGmailDriver_Send_TokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"unauthorized_client","error_description":"Client is unauthorized to retrieve access tokens using this method."}`))
+	}))
+	defer server.Close()
+
+	driver, _ := NewGmailDriver(map[string]any{
+		"credentials_json": testGmailCredentials(t, server.URL),
+		"subject":          "user@example.com",
+		"base_url":         server.URL,
+	})
+	_, err := driver.Send(context.Background(), &Message{
+		From: "user@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("expected ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestGmailDriver_Send_TooLarge(t *testing.T) {
+	fake := &fakeGmail{}
+	driver := newTestGmailDriver(t, fake, map[string]any{"max_message_size": "1KB"})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:        "user@example.com",
+		To:          []string{"a@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "data.bin", Content: make([]byte, 2048)}},
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+	if fake.tokenCalls != 0 || fake.path != "" {
+		t.Error("expected no requests for oversized message")
+	}
+}
+
+func TestGmailDriver_Send_TooLargeWithBcc(t *testing.T) {
+	newMsg := func() *Message {
+		return &Message{
+			From:     "user@example.com",
+			To:       []string{"a@example.com"},
+			Subject:  "Test",
+			BodyText: "Hello",
+		}
+	}
+	body, err := newSMTPBody(newMsg())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 上限只比正文多几个字节，Bcc 头计入大小后超出
+	fake := &fakeGmail{}
+	driver := newTestGmailDriver(t, fake, map[string]any{"max_message_size": int(body.Size()) + 16})
+	if defaults := newTestGmailDriver(t, &fakeGmail{}, nil); defaults.config.MaxMessageSize != GmailDefaultMaxMessageSize {
+		t.Errorf("expected default max message size %d, got %d", GmailDefaultMaxMessageSize, defaults.config.MaxMessageSize)
+	}
+
+	if _, err := driver.Send(context.Background(), newMsg()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := newMsg()
+	msg.Bcc = []string{"bcc1@example.com", "bcc2@example.com"}
+	if _, err := driver.Send(context.Background(), msg); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+}
+
+func TestDefaultRegistry_Gmail(t *testing.T) {
+	if !DefaultRegistry.Has(DriverGmail) {
+		t.Error("expected gmail driver to be registered")
+	}
+}
@@ -0,0 +1,415 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverGraph Microsoft Graph 驱动名称
+	DriverGraph = "graph"
+
+	// GraphDefaultBaseURL Graph API 默认地址
+	GraphDefaultBaseURL = "https://graph.microsoft.com/v1.0"
+
+	// GraphDefaultScope 客户端凭据授权的默认 scope
+	GraphDefaultScope = "https://graph.microsoft.com/.default"
+
+	// GraphUploadThreshold 超过该大小的附件通过上传会话发送（Graph 单次请求上限约 4MB）
+	GraphUploadThreshold = 3 << 20
+
+	// GraphDefaultMaxMessageSize Exchange Online 单封邮件大小上限（150MB，租户可配置更低的限制）
+	GraphDefaultMaxMessageSize = 150 << 20
+)
+
+// GraphConfig Microsoft Graph 驱动配置
+type GraphConfig struct {
+	// TenantID 租户 ID
+	TenantID string `mapstructure:"tenant_id"`
+
+	// ClientID 应用（客户端）ID
+	ClientID string `mapstructure:"client_id"`
+
+	// ClientSecret 客户端密钥
+	ClientSecret string `mapstructure:"client_secret"`
+
+	// UserID 发信邮箱（用户 ID 或 UPN，可选，默认使用 From 地址）
+	UserID string `mapstructure:"user_id"`
+
+	// SaveToSentItems 是否保存到已发送邮件（可选，默认 true）
+	SaveToSentItems bool `mapstructure:"save_to_sent_items"`
+
+	// TokenURL 令牌地址（可选，默认 https://login.microsoftonline.com/{tenant}/oauth2/v2.0/token）
+	TokenURL string `mapstructure:"token_url"`
+
+	// BaseURL Graph API 地址（可选，默认 https://graph.microsoft.com/v1.0）
+	BaseURL string `mapstructure:"base_url"`
+
+	// Scope 授权范围（可选，默认 https://graph.microsoft.com/.default）
+	Scope string `mapstructure:"scope"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 正文与附件总大小上限（可选，默认 150MB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// GraphDriver Microsoft Graph 邮件驱动（sendMail）
+type GraphDriver struct {
+	config *GraphConfig
+	client *http.Client
+
+	tokens oauthTokenCache
+}
+
+// NewGraphDriver 创建 Microsoft Graph 驱动
+func NewGraphDriver(config map[string]any) (Driver, error) {
+	cfg := &GraphConfig{
+		SaveToSentItems: true,
+		BaseURL:         GraphDefaultBaseURL,
+		Scope:           GraphDefaultScope,
+		Timeout:         30 * time.Second,
+		MaxMessageSize:  GraphDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if tenantID, ok := config["tenant_id"].(string); ok {
+		cfg.TenantID = tenantID
+	}
+	if clientID, ok := config["client_id"].(string); ok {
+		cfg.ClientID = clientID
+	}
+	if secret, ok := config["client_secret"].(string); ok {
+		cfg.ClientSecret = secret
+	}
+	if userID, ok := config["user_id"].(string); ok {
+		cfg.UserID = userID
+	}
+	if save, ok := config["save_to_sent_items"].(bool); ok {
+		cfg.SaveToSentItems = save
+	}
+	if tokenURL, ok := config["token_url"].(string); ok && tokenURL != "" {
+		cfg.TokenURL = tokenURL
+	}
+	if baseURL, ok := config["base_url"].(string); ok && baseURL != "" {
+		cfg.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if scope, ok := config["scope"].(string); ok && scope != "" {
+		cfg.Scope = scope
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	if cfg.TokenURL == "" && cfg.TenantID != "" {
+		cfg.TokenURL = fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", url.PathEscape(cfg.TenantID))
+	}
+
+	driver := &GraphDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// Name 驱动名称
+func (d *GraphDriver) Name() string {
+	return DriverGraph
+}
+
+// Validate 验证配置
+func (d *GraphDriver) Validate() error {
+	if d.config.TenantID == "" {
+		return ErrDriverConfig.WithMsg("Graph TenantID 不能为空")
+	}
+	if d.config.ClientID == "" || d.config.ClientSecret == "" {
+		return ErrDriverConfig.WithMsg("Graph ClientID 与 ClientSecret 不能为空")
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("Graph MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+// 附件均不超过 GraphUploadThreshold 时直接调用 sendMail，否则先创建草稿、通过上传会话添加附件后发送
+func (d *GraphDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireImmediate(); err != nil {
+		return nil, err
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+
+	user := d.config.UserID
+	if user == "" {
+		user = headerAddress(msg.From)
+	}
+	userPath := "/users/" + url.PathEscape(user)
+
+	size, known, err := graphMessageSize(msg)
+	if err != nil {
+		return nil, err
+	}
+	if max := d.config.MaxMessageSize; max > 0 && size > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", size, max)
+	}
+	if !known || size-graphBodySize(msg) > GraphUploadThreshold {
+		return d.sendDraft(ctx, userPath, msg)
+	}
+
+	message, err := d.buildMessage(msg, true)
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]any{
+		"message":         message,
+		"saveToSentItems": d.config.SaveToSentItems,
+	}
+	if err := d.call(ctx, http.MethodPost, userPath+"/sendMail", payload, nil); err != nil {
+		return nil, err
+	}
+
+	// sendMail 返回 202 且不返回消息 ID
+	return &Result{
+		Status:  "accepted",
+		Success: true,
+	}, nil
+}
+
+// graphMessageSize 估算邮件大小（正文与附件原始大小之和）
+// 存在大小未知的流式附件时 known 为 false，此时只统计已知部分，需要使用草稿与上传会话
+func graphMessageSize(msg *Message) (size int64, known bool, err error) {
+	size, known = graphBodySize(msg), true
+	for i := range msg.Attachments {
+		n, err := msg.Attachments[i].ContentSize()
+		if err != nil {
+			return 0, false, err
+		}
+		if n < 0 {
+			known = false
+			continue
+		}
+		size += n
+	}
+	return size, known, nil
+}
+
+// graphBodySize 正文大小（Graph 只发送一种正文）
+func graphBodySize(msg *Message) int64 {
+	if msg.BodyHTML != "" {
+		return int64(len(msg.BodyHTML))
+	}
+	return int64(len(msg.BodyText))
+}
+
+// buildMessage 构建 Graph message 资源，withAttachments 为 false 时不包含附件
+func (d *GraphDriver) buildMessage(msg *Message, withAttachments bool) (map[string]any, error) {
+	message := map[string]any{
+		"subject":      msg.Subject,
+		"toRecipients": graphRecipients(msg.To),
+	}
+
+	// Graph 只支持单一正文，优先使用 HTML
+	if msg.BodyHTML != "" {
+		message["body"] = map[string]string{"contentType": "HTML", "content": msg.BodyHTML}
+	} else {
+		message["body"] = map[string]string{"contentType": "Text", "content": msg.BodyText}
+	}
+
+	if msg.FromName != "" {
+		message["from"] = graphRecipient(msg.From, msg.FromName)
+	}
+	if len(msg.Cc) > 0 {
+		message["ccRecipients"] = graphRecipients(msg.Cc)
+	}
+	if len(msg.Bcc) > 0 {
+		message["bccRecipients"] = graphRecipients(msg.Bcc)
+	}
+	if msg.ReplyTo != "" {
+		message["replyTo"] = graphRecipients([]string{msg.ReplyTo})
+	}
+
+	if msg.Importance != "" {
+		message["importance"] = string(msg.Importance)
+	}
+
+	// 自定义头: Graph 只支持 X- 开头的头
+	var headers []map[string]string
+	for _, name := range sortedKeys(msg.Headers) {
+		if !strings.HasPrefix(strings.ToUpper(name), "X-") {
+			return nil, ErrInvalidMessage.WithMsgf("Graph 只支持 X- 开头的自定义头: %s", name)
+		}
+		headers = append(headers, map[string]string{"name": name, "value": msg.Headers[name]})
+	}
+	if len(headers) > 0 {
+		message["internetMessageHeaders"] = headers
+	}
+
+	if len(msg.Categories) > 0 {
+		message["categories"] = msg.Categories
+	}
+
+	if withAttachments && len(msg.Attachments) > 0 {
+		attachments := make([]map[string]any, 0, len(msg.Attachments))
+		for i := range msg.Attachments {
+			item, err := graphFileAttachment(&msg.Attachments[i], nil)
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, item)
+		}
+		message["attachments"] = attachments
+	}
+
+	return message, nil
+}
+
+// graphFileAttachment 构建 fileAttachment 资源，data 为 nil 时读取附件内容
+func graphFileAttachment(att *Attachment, data []byte) (map[string]any, error) {
+	if data == nil {
+		// API 请求需要完整内容，流式附件在此读取
+		var err error
+		if data, err = att.ReadAll(); err != nil {
+			return nil, err
+		}
+	}
+	item := map[string]any{
+		"@odata.type":  "#microsoft.graph.fileAttachment",
+		"name":         att.Filename,
+		"contentType":  att.ContentType,
+		"contentBytes": base64.StdEncoding.EncodeToString(data),
+	}
+	if att.Inline {
+		// 内联图片
+		item["isInline"] = true
+		item["contentId"] = att.ContentID
+	}
+	return item, nil
+}
+
+// graphRecipient 构建 recipient 资源
+func graphRecipient(addr, name string) map[string]any {
+	emailAddress := map[string]string{"address": headerAddress(addr)}
+	if name != "" {
+		emailAddress["name"] = name
+	}
+	return map[string]any{"emailAddress": emailAddress}
+}
+
+// graphRecipients 构建 recipient 列表
+func graphRecipients(addrs []string) []map[string]any {
+	out := make([]map[string]any, 0, len(addrs))
+	for _, addr := range addrs {
+		out = append(out, graphRecipient(addr, ""))
+	}
+	return out
+}
+
+// call 调用 Graph API，payload 为 nil 时不发送请求体，out 不为 nil 时解析响应体
+func (d *GraphDriver) call(ctx context.Context, method, path string, payload, out any) error {
+	token, err := d.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if payload != nil {
+		if body, err = json.Marshal(payload); err != nil {
+			return ErrSendFailed.Wrap(err).WithMsg("序列化请求失败")
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, d.config.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	respBody, err := d.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return ErrSendFailed.Wrap(err).WithMsg("解析响应失败")
+		}
+	}
+	return nil
+}
+
+// do 发送请求并返回成功响应体，失败时解析 Graph 错误
+func (d *GraphDriver) do(ctx context.Context, req *http.Request) ([]byte, error) {
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		graphErr := newGraphError(resp, respBody)
+		if resp.StatusCode == http.StatusUnauthorized {
+			// 令牌可能已被吊销，下次发送时重新获取
+			d.tokens.reset()
+		}
+		return nil, graphErrorCode(graphErr).Wrap(graphErr).WithMsgf("Graph API 错误: %s - %s", graphErr.Code, graphErr.Message)
+	}
+	return respBody, nil
+}
+
+// accessToken 获取访问令牌（客户端凭据授权），过期前复用缓存
+func (d *GraphDriver) accessToken(ctx context.Context) (string, error) {
+	return d.tokens.get(ctx, func(ctx context.Context) (string, time.Duration, error) {
+		form := url.Values{}
+		form.Set("grant_type", "client_credentials")
+		form.Set("client_id", d.config.ClientID)
+		form.Set("client_secret", d.config.ClientSecret)
+		form.Set("scope", d.config.Scope)
+		return requestOAuthToken(ctx, d.client, d.config.TokenURL, form)
+	})
+}
+
+func init() {
+	// 注册 Microsoft Graph 驱动到默认注册表
+	RegisterDriver(DriverGraph, NewGraphDriver)
+}
@@ -0,0 +1,86 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KOMKZ/go-yogan-framework/errcode"
+)
+
+// GraphError Microsoft Graph API 返回的错误，可通过 errors.As 获取
+type GraphError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+
+	// Code 错误码（如 ErrorInvalidRecipients、ErrorAccessDenied）
+	Code string
+
+	// Message 错误描述
+	Message string
+
+	// RequestID 请求 ID（request-id 头），用于向微软排查问题
+	RequestID string
+}
+
+// Error 实现 error 接口
+func (e *GraphError) Error() string {
+	return fmt.Sprintf("graph %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// newGraphError 从错误响应解析 GraphError
+func newGraphError(resp *http.Response, body []byte) *GraphError {
+	e := &GraphError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("Request-Id"),
+	}
+
+	var out struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &out)
+
+	e.Code = out.Error.Code
+	if e.Code == "" {
+		e.Code = http.StatusText(resp.StatusCode)
+	}
+	e.Message = out.Error.Message
+	if e.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// graphErrorCode 将 Graph 错误映射为组件错误码
+func graphErrorCode(e *GraphError) *errcode.AppError {
+	switch e.Code {
+	case "ErrorInvalidRecipients", "ErrorRecipientNotFound":
+		return ErrInvalidRecipient
+	case "ErrorMessageSizeExceeded":
+		return ErrMessageTooLarge
+	case "ErrorInvalidUser", "MailboxNotEnabledForRESTAPI", "ErrorSendAsDenied", "ResourceNotFound":
+		// 发信邮箱不存在或应用无权以该邮箱发信
+		return ErrDriverConfig
+	case "ApplicationThrottled", "MailboxConcurrency", "ErrorTooManyObjectsOpened":
+		return ErrRateLimited
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthFailed
+	case http.StatusNotFound:
+		return ErrDriverConfig
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusRequestEntityTooLarge:
+		return ErrMessageTooLarge
+	case http.StatusBadRequest:
+		return ErrInvalidMessage
+	default:
+		return ErrSendFailed
+	}
+}
@@ -0,0 +1,433 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewGraphDriver(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]any
+		tokenURL string
+		wantErr  bool
+	}{
+		{
+			name:     "default token url",
+			config:   map[string]any{"tenant_id": "contoso", "client_id": "app", "client_secret": "secret"},
+			tokenURL: "https://login.microsoftonline.com/contoso/oauth2/v2.0/token",
+		},
+		{
+			name:     "token url override",
+			config:   map[string]any{"tenant_id": "contoso", "client_id": "app", "client_secret": "secret", "token_url": "http://127.0.0.1/token"},
+			tokenURL: "http://127.0.0.1/token",
+		},
+		{
+			name:    "missing tenant",
+			config:  map[string]any{"client_id": "app", "client_secret": "secret"},
+			wantErr: true,
+		},
+		{
+			name:    "missing secret",
+			config:  map[string]any{"tenant_id": "contoso", "client_id": "app"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := NewGraphDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGraphDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && driver.(*GraphDriver).config.TokenURL != tt.tokenURL {
+				t.Errorf("unexpected token url: %s", driver.(*GraphDriver).config.TokenURL)
+			}
+		})
+	}
+}
+
+// graphRequest 测试服务器收到的 Graph 请求
+type graphRequest struct {
+	method string
+	path   string
+	auth   string
+	header http.Header
+	body   []byte
+}
+
+// fakeGraph 本地模拟的令牌与 Graph 接口
+type fakeGraph struct {
+	mu          sync.Mutex
+	tokenCalls  int
+	tokenForm   map[string]string
+	requests    []graphRequest
+	uploads     [][]byte
+	handleGraph func(w http.ResponseWriter, r *http.Request) bool
+}
+
+func (f *fakeGraph) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.URL.Path == "/token":
+		f.tokenCalls++
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ParseForm()
+		f.tokenForm = map[string]string{}
+		for k := range r.PostForm {
+			f.tokenForm[k] = r.PostForm.Get(k)
+		}
+		fmt.Fprintf(w, `{"token_type":"Bearer","expires_in":3599,"access_token":"token-%d"}`, f.tokenCalls)
+		return
+	case strings.HasPrefix(r.URL.Path, "/upload/"):
+		f.requests = append(f.requests, graphRequest{method: r.Method, path: r.URL.Path, auth: r.Header.Get("Authorization"), header: r.Header.Clone()})
+		f.uploads = append(f.uploads, body)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	f.requests = append(f.requests, graphRequest{method: r.Method, path: r.URL.Path, auth: r.Header.Get("Authorization"), header: r.Header.Clone(), body: body})
+	if f.handleGraph != nil && f.handleGraph(w, r) {
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// newTestGraphDriver 创建指向本地服务器的 Graph 驱动
+func newTestGraphDriver(t *testing.T, fake *fakeGraph, config map[string]any) (*GraphDriver, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg := map[string]any{
+		"tenant_id":     "contoso",
+		"client_id":     "app",
+		"client_secret": "secret",
+		"token_url":     server.URL + "/token",
+		"base_url":      server.URL + "/v1.0",
+	}
+	for k, v := range config {
+		cfg[k] = v
+	}
+	driver, err := NewGraphDriver(cfg)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver.(*GraphDriver), server
+}
+
+func TestGraphDriver_Send_SendMail(t *testing.T) {
+	fake := &fakeGraph{}
+	driver, _ := newTestGraphDriver(t, fake, map[string]any{"save_to_sent_items": false})
+
+	msg := &Message{
+		From:       "noreply@contoso.com",
+		FromName:   "Contoso",
+		To:         []string{"a@example.com"},
+		Cc:         []string{"cc@example.com"},
+		Bcc:        []string{"bcc@example.com"},
+		ReplyTo:    "reply@contoso.com",
+		Subject:    "Test",
+		BodyHTML:   `<img src="cid:logo">`,
+		BodyText:   "Hello",
+		Headers:    map[string]string{"X-Campaign": "welcome"},
+		Importance: ImportanceHigh,
+		Categories: []string{"Blue category"},
+		Attachments: []Attachment{
+			{Filename: "report.pdf", Content: []byte("%PDF-1.4")},
+			{Filename: "logo.png", Content: []byte("png"), ContentType: "image/png", Inline: true, ContentID: "logo"},
+		},
+	}
+	result, err := driver.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success || result.Status != "accepted" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// 第二次发送复用缓存的令牌
+	if _, err := driver.Send(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.tokenCalls != 1 {
+		t.Errorf("expected token to be cached, got %d token requests", fake.tokenCalls)
+	}
+	if fake.tokenForm["grant_type"] != "client_credentials" || fake.tokenForm["client_id"] != "app" || fake.tokenForm["scope"] != GraphDefaultScope {
+		t.Errorf("unexpected token request: %v", fake.tokenForm)
+	}
+
+	req := fake.requests[0]
+	if req.method != http.MethodPost || req.path != "/v1.0/users/noreply@contoso.com/sendMail" || req.auth != "Bearer token-1" {
+		t.Errorf("unexpected request: %s %s %s", req.method, req.path, req.auth)
+	}
+
+	var payload struct {
+		Message         map[string]any `json:"message"`
+		SaveToSentItems bool           `json:"saveToSentItems"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.SaveToSentItems {
+		t.Error("expected saveToSentItems false")
+	}
+	m := payload.Message
+	if m["body"].(map[string]any)["contentType"] != "HTML" || m["importance"] != "high" {
+		t.Errorf("unexpected body/importance: %v %v", m["body"], m["importance"])
+	}
+	if m["from"].(map[string]any)["emailAddress"].(map[string]any)["name"] != "Contoso" {
+		t.Errorf("unexpected from: %v", m["from"])
+	}
+	if len(m["ccRecipients"].([]any)) != 1 || len(m["bccRecipients"].([]any)) != 1 || len(m["replyTo"].([]any)) != 1 {
+		t.Errorf("unexpected recipients: %v", m)
+	}
+	headers := m["internetMessageHeaders"].([]any)
+	if len(headers) != 1 || headers[0].(map[string]any)["name"] != "X-Campaign" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+
+	attachments := m["attachments"].([]any)
+	pdf := attachments[0].(map[string]any)
+	if pdf["@odata.type"] != "#microsoft.graph.fileAttachment" || pdf["contentType"] != "application/pdf" ||
+		pdf["contentBytes"] != base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")) {
+		t.Errorf("unexpected attachment: %v", pdf)
+	}
+	if logo := attachments[1].(map[string]any); logo["isInline"] != true || logo["contentId"] != "logo" {
+		t.Errorf("unexpected inline attachment: %v", logo)
+	}
+}
+
+func TestGraphDriver_Send_UploadSession(t *testing.T) {
+	var serverURL string
+	fake := &fakeGraph{}
+	fake.handleGraph = func(w http.ResponseWriter, r *http.Request) bool {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1.0/users/mailbox@contoso.com/messages":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"draft-1"}`))
+		case strings.HasSuffix(r.URL.Path, "/attachments/createUploadSession"):
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"uploadUrl":"%s/upload/session-1"}`, serverURL)
+		case strings.HasSuffix(r.URL.Path, "/attachments"):
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		default:
+			return false
+		}
+		return true
+	}
+	driver, server := newTestGraphDriver(t, fake, map[string]any{"user_id": "mailbox@contoso.com"})
+	serverURL = server.URL
+
+	large := bytes.Repeat([]byte("x"), 4<<20)
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "noreply@contoso.com",
+		To:       []string{"a@example.com"},
+		Subject:  "Large",
+		BodyText: "See attached",
+		Attachments: []Attachment{
+			{Filename: "small.txt", Content: []byte("small")},
+			{Filename: "large.bin", Reader: bytes.NewReader(large), ContentType: "application/octet-stream"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessageID != "draft-1" || !result.Success {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	var paths []string
+	for _, req := range fake.requests {
+		paths = append(paths, req.method+" "+req.path)
+	}
+	want := []string{
+		"POST /v1.0/users/mailbox@contoso.com/messages",
+		"POST /v1.0/users/mailbox@contoso.com/messages/draft-1/attachments",
+		"POST /v1.0/users/mailbox@contoso.com/messages/draft-1/attachments/createUploadSession",
+		"PUT /upload/session-1",
+		"PUT /upload/session-1",
+		"POST /v1.0/users/mailbox@contoso.com/messages/draft-1/send",
+	}
+	if strings.Join(paths, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected requests:\n%s", strings.Join(paths, "\n"))
+	}
+
+	var session struct {
+		AttachmentItem map[string]any `json:"AttachmentItem"`
+	}
+	json.Unmarshal(fake.requests[2].body, &session)
+	if session.AttachmentItem["name"] != "large.bin" || session.AttachmentItem["size"] != float64(len(large)) {
+		t.Errorf("unexpected upload session request: %v", session.AttachmentItem)
+	}
+
+	first, second := fake.requests[3], fake.requests[4]
+	if first.auth != "" {
+		t.Error("expected upload requests without Authorization header")
+	}
+	if got := first.header.Get("Content-Range"); got != fmt.Sprintf("bytes 0-%d/%d", graphUploadChunkSize-1, len(large)) {
+		t.Errorf("unexpected first range: %s", got)
+	}
+	if got := second.header.Get("Content-Range"); got != fmt.Sprintf("bytes %d-%d/%d", graphUploadChunkSize, len(large)-1, len(large)) {
+		t.Errorf("unexpected second range: %s", got)
+	}
+	if !bytes.Equal(append(fake.uploads[0], fake.uploads[1]...), large) {
+		t.Error("uploaded content mismatch")
+	}
+	if len(fake.requests[5].body) != 0 {
+		t.Errorf("expected empty send body, got %q", fake.requests[5].body)
+	}
+}
+
+func TestGraphDriver_Send_TooLarge(t *testing.T) {
+	fake := &fakeGraph{}
+	driver, _ := newTestGraphDriver(t, fake, map[string]any{"max_message_size": "1MB"})
+	if driver.config.MaxMessageSize != 1<<20 {
+		t.Errorf("expected max message size 1MB, got %d", driver.config.MaxMessageSize)
+	}
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:        "noreply@contoso.com",
+		To:          []string{"user@example.com"},
+		Subject:     "Report",
+		BodyText:    "See attached",
+		Attachments: []Attachment{{Filename: "report.bin", Content: make([]byte, 2<<20)}},
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+	if len(fake.requests) != 0 || fake.tokenCalls != 0 {
+		t.Errorf("expected no requests before size check, got %d requests", len(fake.requests))
+	}
+}
+
+func TestGraphDriver_Send_InvalidMessage(t *testing.T) {
+	fake := &fakeGraph{}
+	driver, _ := newTestGraphDriver(t, fake, nil)
+
+	tests := []struct {
+		name string
+		msg  *Message
+	}{
+		{"non X- header", &Message{
+			From: "noreply@contoso.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+			Headers: map[string]string{"Priority": "urgent"},
+		}},
+		{"invalid importance", &Message{
+			From: "noreply@contoso.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+			Importance: "urgent",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := driver.Send(context.Background(), tt.msg); !errors.Is(err, ErrInvalidMessage) {
+				t.Errorf("expected ErrInvalidMessage, got %v", err)
+			}
+		})
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("expected no requests, got %d", len(fake.requests))
+	}
+}
+
+func TestGraphDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		code    string
+		wantErr error
+	}{
+		{"invalid recipients", http.StatusBadRequest, "ErrorInvalidRecipients", ErrInvalidRecipient},
+		{"access denied", http.StatusForbidden, "ErrorAccessDenied", ErrAuthFailed},
+		{"mailbox not found", http.StatusNotFound, "ErrorInvalidUser", ErrDriverConfig},
+		{"throttled", http.StatusTooManyRequests, "ApplicationThrottled", ErrRateLimited},
+		{"too large", http.StatusRequestEntityTooLarge, "ErrorMessageSizeExceeded", ErrMessageTooLarge},
+		{"bad request", http.StatusBadRequest, "ErrorInvalidRequest", ErrInvalidMessage},
+		{"server error", http.StatusServiceUnavailable, "ServiceNotAvailable", ErrSendFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGraph{handleGraph: func(w http.ResponseWriter, r *http.Request) bool {
+				w.Header().Set("Request-Id", "req-1")
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"error":{"code":"%s","message":"error"}}`, tt.code)
+				return true
+			}}
+			driver, _ := newTestGraphDriver(t, fake, nil)
+
+			_, err := driver.Send(context.Background(), &Message{
+				From: "noreply@contoso.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var graphErr *GraphError
+			if !errors.As(err, &graphErr) || graphErr.Code != tt.code || graphErr.RequestID != "req-1" {
+				t.Errorf("expected GraphError %s, got %v", tt.code, graphErr)
+			}
+		})
+	}
+}
+
+func TestGraphDriver_Send_Unauthorized(t *testing.T) {
+	fake := &fakeGraph{handleGraph: func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"code":"InvalidAuthenticationToken","message":"Access token has expired."}}`))
+		return true
+	}}
+	driver, _ := newTestGraphDriver(t, fake, nil)
+	msg := &Message{From: "noreply@contoso.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := driver.Send(context.Background(), msg); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("expected ErrAuthFailed, got %v", err)
+		}
+	}
+	// 401 后清除缓存的令牌
+	if fake.tokenCalls != 2 {
+		t.Errorf("expected token to be refreshed after 401, got %d token requests", fake.tokenCalls)
+	}
+}
+
+func TestGraphDriver_Send_TokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided."}`))
+	}))
+	defer server.Close()
+
+	driver, _ := NewGraphDriver(map[string]any{
+		"tenant_id":     "contoso",
+		"client_id":     "app",
+		"client_secret": "wrong",
+		"token_url":     server.URL,
+		"base_url":      server.URL,
+	})
+	_, err := driver.Send(context.Background(), &Message{
+		From: "noreply@contoso.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("expected ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestDefaultRegistry_Graph(t *testing.T) {
+	if !DefaultRegistry.Has(DriverGraph) {
+		t.Error("expected graph driver to be registered")
+	}
+}
@@ -0,0 +1,142 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// graphUploadChunkSize 上传分片大小（必须为 320KiB 的整数倍，且不超过 4MB）
+const graphUploadChunkSize = 10 * 320 << 10
+
+// sendDraft 创建草稿、逐个添加附件后发送，用于包含大附件的邮件
+// 草稿发送后总是保存到已发送邮件，SaveToSentItems 在此模式下不生效
+func (d *GraphDriver) sendDraft(ctx context.Context, userPath string, msg *Message) (*Result, error) {
+	message, err := d.buildMessage(msg, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var draft struct {
+		ID string `json:"id"`
+	}
+	if err := d.call(ctx, http.MethodPost, userPath+"/messages", message, &draft); err != nil {
+		return nil, err
+	}
+	messagePath := userPath + "/messages/" + url.PathEscape(draft.ID)
+
+	for i := range msg.Attachments {
+		if err := d.addAttachment(ctx, messagePath, &msg.Attachments[i]); err != nil {
+			d.deleteDraft(messagePath)
+			return nil, err
+		}
+	}
+
+	if err := d.call(ctx, http.MethodPost, messagePath+"/send", nil, nil); err != nil {
+		d.deleteDraft(messagePath)
+		return nil, err
+	}
+	return &Result{
+		MessageID: draft.ID,
+		Status:    "accepted",
+		Success:   true,
+	}, nil
+}
+
+// deleteDraft 发送失败时尽力删除草稿，避免在邮箱中残留
+func (d *GraphDriver) deleteDraft(messagePath string) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+	defer cancel()
+	_ = d.call(ctx, http.MethodDelete, messagePath, nil, nil)
+}
+
+// addAttachment 向草稿添加附件，超过阈值时使用上传会话分片上传
+func (d *GraphDriver) addAttachment(ctx context.Context, messagePath string, att *Attachment) error {
+	size, err := att.ContentSize()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if size < 0 {
+		// 大小未知的流式附件先读入内存
+		if data, err = att.ReadAll(); err != nil {
+			return err
+		}
+		size = int64(len(data))
+	}
+
+	if size <= GraphUploadThreshold {
+		item, err := graphFileAttachment(att, data)
+		if err != nil {
+			return err
+		}
+		return d.call(ctx, http.MethodPost, messagePath+"/attachments", item, nil)
+	}
+
+	item := map[string]any{
+		"attachmentType": "file",
+		"name":           att.Filename,
+		"size":           size,
+		"contentType":    att.ContentType,
+	}
+	if att.Inline {
+		item["isInline"] = true
+		item["contentId"] = att.ContentID
+	}
+	var session struct {
+		UploadURL string `json:"uploadUrl"`
+	}
+	if err := d.call(ctx, http.MethodPost, messagePath+"/attachments/createUploadSession",
+		map[string]any{"AttachmentItem": item}, &session); err != nil {
+		return err
+	}
+
+	var r io.Reader
+	if data != nil {
+		r = bytes.NewReader(data)
+	} else {
+		rc, err := att.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		r = attachmentReader{r: rc, name: att.Filename}
+	}
+	return d.upload(ctx, session.UploadURL, r, size)
+}
+
+// upload 按分片上传附件内容（上传地址已包含授权信息，不发送 Authorization 头）
+func (d *GraphDriver) upload(ctx context.Context, uploadURL string, r io.Reader, size int64) error {
+	buf := make([]byte, graphUploadChunkSize)
+	var offset int64
+	for offset < size {
+		n, err := io.ReadFull(r, buf[:min(int64(len(buf)), size-offset)])
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return ErrInvalidMessage.WithMsgf("附件内容小于声明的大小 %d 字节", size)
+			}
+			if isAppError(err) {
+				return err
+			}
+			return ErrInvalidMessage.Wrap(err).WithMsg("读取附件失败")
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(buf[:n]))
+		if err != nil {
+			return ErrSendFailed.Wrap(err).WithMsg("创建上传请求失败")
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(n)-1, size))
+
+		if _, err := d.do(ctx, req); err != nil {
+			return err
+		}
+		offset += int64(n)
+	}
+	return nil
+}
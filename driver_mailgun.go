@@ -0,0 +1,466 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverMailgun Mailgun 驱动名称
+	DriverMailgun = "mailgun"
+
+	// MailgunBaseURLUS Mailgun 美国区域 API 地址
+	MailgunBaseURLUS = "https://api.mailgun.net"
+
+	// MailgunBaseURLEU Mailgun 欧洲区域 API 地址
+	MailgunBaseURLEU = "https://api.eu.mailgun.net"
+
+	// MailgunModeForm 以表单字段提交邮件内容（/messages）
+	MailgunModeForm = "form"
+
+	// MailgunModeMIME 提交组件构建的完整 MIME 邮件（/messages.mime）
+	MailgunModeMIME = "mime"
+
+	// MailgunDefaultMaxMessageSize Mailgun 单封邮件大小上限（25MB）
+	MailgunDefaultMaxMessageSize = 25 << 20
+)
+
+// MailgunConfig Mailgun 驱动配置
+type MailgunConfig struct {
+	// APIKey Mailgun API Key
+	APIKey string `mapstructure:"api_key"`
+
+	// Domain 发信域名
+	Domain string `mapstructure:"domain"`
+
+	// Region 区域（可选，us 或 eu，默认 us）
+	Region string `mapstructure:"region"`
+
+	// BaseURL API 基础地址（可选，优先于 region）
+	BaseURL string `mapstructure:"base_url"`
+
+	// Mode 提交方式（可选，form 或 mime，默认 form）
+	Mode string `mapstructure:"mode"`
+
+	// Tracking 是否开启跟踪（可选，yes/no，未设置时使用域名配置）
+	Tracking string `mapstructure:"tracking"`
+
+	// TrackingClicks 点击跟踪（可选，yes/no/htmlonly）
+	TrackingClicks string `mapstructure:"tracking_clicks"`
+
+	// TrackingOpens 打开跟踪（可选，yes/no）
+	TrackingOpens string `mapstructure:"tracking_opens"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 请求体最大字节数（可选，默认 25MB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// MailgunDriver Mailgun 邮件驱动（Messages API）
+type MailgunDriver struct {
+	config *MailgunConfig
+	client *http.Client
+}
+
+// NewMailgunDriver 创建 Mailgun 驱动
+func NewMailgunDriver(config map[string]any) (Driver, error) {
+	cfg := &MailgunConfig{
+		Mode:           MailgunModeForm,
+		Timeout:        30 * time.Second,
+		MaxMessageSize: MailgunDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if apiKey, ok := config["api_key"].(string); ok {
+		cfg.APIKey = apiKey
+	}
+	if domain, ok := config["domain"].(string); ok {
+		cfg.Domain = domain
+	}
+	if region, ok := config["region"].(string); ok {
+		cfg.Region = strings.ToLower(region)
+	}
+	if baseURL, ok := config["base_url"].(string); ok && baseURL != "" {
+		cfg.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if mode, ok := config["mode"].(string); ok && mode != "" {
+		cfg.Mode = mode
+	}
+	if v, ok := mailgunYesNo(config["tracking"]); ok {
+		cfg.Tracking = v
+	}
+	if v, ok := mailgunYesNo(config["tracking_clicks"]); ok {
+		cfg.TrackingClicks = v
+	}
+	if v, ok := mailgunYesNo(config["tracking_opens"]); ok {
+		cfg.TrackingOpens = v
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = MailgunBaseURLUS
+		if cfg.Region == "eu" {
+			cfg.BaseURL = MailgunBaseURLEU
+		}
+	}
+
+	driver := &MailgunDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// mailgunYesNo 解析跟踪选项，YAML 中的布尔值转换为 yes/no
+func mailgunYesNo(v any) (string, bool) {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return "yes", true
+		}
+		return "no", true
+	case string:
+		if val != "" {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// Name 驱动名称
+func (d *MailgunDriver) Name() string {
+	return DriverMailgun
+}
+
+// Validate 验证配置
+func (d *MailgunDriver) Validate() error {
+	if d.config.APIKey == "" {
+		return ErrDriverConfig.WithMsg("Mailgun API Key 不能为空")
+	}
+	if d.config.Domain == "" {
+		return ErrDriverConfig.WithMsg("Mailgun Domain 不能为空")
+	}
+	switch d.config.Region {
+	case "", "us", "eu":
+	default:
+		return ErrDriverConfig.WithMsgf("Mailgun Region 无效: %s", d.config.Region)
+	}
+	switch d.config.Mode {
+	case MailgunModeForm, MailgunModeMIME:
+	default:
+		return ErrDriverConfig.WithMsgf("Mailgun Mode 无效: %s", d.config.Mode)
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("Mailgun MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+func (d *MailgunDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	// MIME 模式由组件构建正文，无法使用 Mailgun 模板
+	if d.config.Mode == MailgunModeMIME {
+		if err := msg.requireBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+
+	// 构建表单
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	path := "/messages"
+	var err error
+	if d.config.Mode == MailgunModeMIME {
+		path = "/messages.mime"
+		err = d.writeMIMEForm(form, msg)
+	} else {
+		err = d.writeForm(form, msg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+
+	return d.doRequest(ctx, path, form.FormDataContentType(), buf.Bytes())
+}
+
+// writeForm 以表单字段写入邮件内容，附件与内联图片作为文件部分
+func (d *MailgunDriver) writeForm(form *multipart.Writer, msg *Message) error {
+	fields := [][2]string{{"from", displayAddress(msg.FromName, msg.From)}}
+	for _, addr := range headerAddresses(msg.To) {
+		fields = append(fields, [2]string{"to", addr})
+	}
+	for _, addr := range headerAddresses(msg.Cc) {
+		fields = append(fields, [2]string{"cc", addr})
+	}
+	for _, addr := range headerAddresses(msg.Bcc) {
+		fields = append(fields, [2]string{"bcc", addr})
+	}
+	fields = append(fields, [2]string{"subject", msg.Subject})
+	if msg.BodyText != "" {
+		fields = append(fields, [2]string{"text", msg.BodyText})
+	}
+	if msg.BodyHTML != "" {
+		fields = append(fields, [2]string{"html", msg.BodyHTML})
+	}
+
+	// 厂商侧模板
+	if msg.TemplateID != "" {
+		fields = append(fields, [2]string{"template", msg.TemplateID})
+		if len(msg.TemplateData) > 0 {
+			data, err := json.Marshal(msg.TemplateData)
+			if err != nil {
+				return ErrInvalidMessage.Wrap(err).WithMsg("序列化模板变量失败")
+			}
+			fields = append(fields, [2]string{"t:variables", string(data)})
+		}
+	}
+
+	// 自定义头（按名称排序，保证请求稳定）
+	if msg.ReplyTo != "" {
+		fields = append(fields, [2]string{"h:Reply-To", headerAddress(msg.ReplyTo)})
+	}
+	for _, name := range sortedKeys(msg.Headers) {
+		fields = append(fields, [2]string{"h:" + name, msg.Headers[name]})
+	}
+
+	fields = append(fields, d.options(msg)...)
+	if err := writeFormFields(form, fields); err != nil {
+		return err
+	}
+
+	// 附件: 内联图片以 Content-ID 作为文件名，HTML 中通过 cid:<ContentID> 引用
+	for i := range msg.Attachments {
+		att := &msg.Attachments[i]
+		field, filename := "attachment", att.Filename
+		if att.Inline {
+			field = "inline"
+			if att.ContentID != "" {
+				filename = att.ContentID
+			}
+		}
+		if err := writeFormFile(form, field, filename, att.ContentType, att); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMIMEForm 写入完整 MIME 邮件，收件人（含密送）通过 to 字段指定
+func (d *MailgunDriver) writeMIMEForm(form *multipart.Writer, msg *Message) error {
+	var fields [][2]string
+	for _, list := range [][]string{msg.To, msg.Cc, msg.Bcc} {
+		for _, addr := range headerAddresses(list) {
+			fields = append(fields, [2]string{"to", addr})
+		}
+	}
+	fields = append(fields, d.options(msg)...)
+	if err := writeFormFields(form, fields); err != nil {
+		return err
+	}
+
+	body, err := newSMTPBody(msg)
+	if err != nil {
+		return err
+	}
+	if max, size := d.config.MaxMessageSize, body.Size(); max > 0 && size > max {
+		return ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", size, max)
+	}
+
+	w, err := form.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+	if _, err := body.WriteTo(w); err != nil {
+		if isAppError(err) {
+			return err
+		}
+		return ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+	return nil
+}
+
+// options 生成 o: 选项与 v: 自定义变量
+func (d *MailgunDriver) options(msg *Message) [][2]string {
+	var fields [][2]string
+	for _, category := range msg.Categories {
+		fields = append(fields, [2]string{"o:tag", category})
+	}
+	if !msg.SendAt.IsZero() {
+		fields = append(fields, [2]string{"o:deliverytime", msg.SendAt.Format(time.RFC1123Z)})
+	}
+	if d.config.Tracking != "" {
+		fields = append(fields, [2]string{"o:tracking", d.config.Tracking})
+	}
+	if d.config.TrackingClicks != "" {
+		fields = append(fields, [2]string{"o:tracking-clicks", d.config.TrackingClicks})
+	}
+	if d.config.TrackingOpens != "" {
+		fields = append(fields, [2]string{"o:tracking-opens", d.config.TrackingOpens})
+	}
+	for _, name := range sortedKeys(msg.Tags) {
+		fields = append(fields, [2]string{"v:" + name, msg.Tags[name]})
+	}
+	return fields
+}
+
+// doRequest 发送 HTTP 请求
+func (d *MailgunDriver) doRequest(ctx context.Context, path, contentType string, body []byte) (*Result, error) {
+	// 发送前检查大小限制
+	if max := d.config.MaxMessageSize; max > 0 && int64(len(body)) > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", len(body), max)
+	}
+
+	endpoint := d.config.BaseURL + "/v3/" + url.PathEscape(d.config.Domain) + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.SetBasicAuth("api", d.config.APIKey)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	// 解析响应
+	return d.parseResponse(resp, respBody)
+}
+
+// parseResponse 解析 Mailgun 响应
+func (d *MailgunDriver) parseResponse(resp *http.Response, body []byte) (*Result, error) {
+	var out struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	}
+	reason := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &out); err == nil && out.Message != "" {
+		reason = out.Message
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return &Result{
+			MessageID: strings.Trim(out.ID, "<>"),
+			Status:    "queued",
+			Success:   true,
+		}, nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrAuthFailed.WithMsgf("Mailgun 认证失败: %s", reason)
+	case http.StatusTooManyRequests:
+		return nil, ErrRateLimited.WithMsgf("Mailgun 限流: %s", reason)
+	case http.StatusRequestEntityTooLarge:
+		return nil, ErrMessageTooLarge.WithMsgf("Mailgun 拒绝: %s", reason)
+	case http.StatusBadRequest:
+		return nil, ErrInvalidMessage.WithMsgf("Mailgun 请求无效: %s", reason)
+	case http.StatusNotFound:
+		// 域名不存在或不属于该账号
+		return nil, ErrDriverConfig.WithMsgf("Mailgun 域名无效: %s", reason)
+	default:
+		return nil, ErrSendFailed.WithMsgf("Mailgun API 错误 (%d): %s", resp.StatusCode, reason)
+	}
+}
+
+// writeFormFields 写入表单字段
+func writeFormFields(form *multipart.Writer, fields [][2]string) error {
+	for _, f := range fields {
+		if err := form.WriteField(f[0], f[1]); err != nil {
+			return ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+		}
+	}
+	return nil
+}
+
+// writeFormFile 写入附件文件部分
+func writeFormFile(form *multipart.Writer, field, filename, contentType string, att *Attachment) error {
+	header := make(map[string][]string)
+	header["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(field), escapeQuotes(filename))}
+	if contentType != "" {
+		header["Content-Type"] = []string{contentType}
+	}
+	w, err := form.CreatePart(header)
+	if err != nil {
+		return ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+
+	rc, err := att.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if _, err := io.Copy(w, attachmentReader{r: rc, name: att.Filename}); err != nil {
+		if isAppError(err) {
+			return err
+		}
+		return ErrSendFailed.Wrap(err).WithMsg("构建请求失败")
+	}
+	return nil
+}
+
+// escapeQuotes 转义 Content-Disposition 参数中的引号与反斜杠
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+// sortedKeys 返回按名称排序的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	// 注册 Mailgun 驱动到默认注册表
+	RegisterDriver(DriverMailgun, NewMailgunDriver)
+}
@@ -0,0 +1,310 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewMailgunDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		baseURL string
+		wantErr bool
+	}{
+		{
+			name:    "default region",
+			config:  map[string]any{"api_key": "key", "domain": "mg.example.com"},
+			baseURL: MailgunBaseURLUS,
+		},
+		{
+			name:    "eu region",
+			config:  map[string]any{"api_key": "key", "domain": "mg.example.com", "region": "EU"},
+			baseURL: MailgunBaseURLEU,
+		},
+		{
+			name:    "base url override",
+			config:  map[string]any{"api_key": "key", "domain": "mg.example.com", "region": "eu", "base_url": "http://127.0.0.1:9000/"},
+			baseURL: "http://127.0.0.1:9000",
+		},
+		{
+			name:    "missing domain",
+			config:  map[string]any{"api_key": "key"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid region",
+			config:  map[string]any{"api_key": "key", "domain": "mg.example.com", "region": "ap"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid mode",
+			config:  map[string]any{"api_key": "key", "domain": "mg.example.com", "mode": "smtp"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := NewMailgunDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMailgunDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && driver.(*MailgunDriver).config.BaseURL != tt.baseURL {
+				t.Errorf("unexpected base url: %s", driver.(*MailgunDriver).config.BaseURL)
+			}
+		})
+	}
+}
+
+// mailgunRequest 测试服务器收到的请求
+type mailgunRequest struct {
+	path   string
+	user   string
+	pass   string
+	fields map[string][]string
+	files  map[string][]mailgunFile
+}
+
+type mailgunFile struct {
+	filename    string
+	contentType string
+	content     string
+}
+
+// newTestMailgunDriver 创建指向本地服务器的 Mailgun 驱动，返回收到的请求
+func newTestMailgunDriver(t *testing.T, config map[string]any) (Driver, *mailgunRequest) {
+	t.Helper()
+	got := &mailgunRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path = r.URL.Path
+		got.user, got.pass, _ = r.BasicAuth()
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		got.fields = r.MultipartForm.Value
+		got.files = make(map[string][]mailgunFile)
+		for field, headers := range r.MultipartForm.File {
+			for _, fh := range headers {
+				f, _ := fh.Open()
+				data, _ := io.ReadAll(f)
+				f.Close()
+				got.files[field] = append(got.files[field], mailgunFile{
+					filename:    fh.Filename,
+					contentType: fh.Header.Get("Content-Type"),
+					content:     string(data),
+				})
+			}
+		}
+		w.Write([]byte(`{"id":"<20240101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := map[string]any{
+		"api_key":  "key-123",
+		"domain":   "mg.example.com",
+		"base_url": server.URL,
+	}
+	for k, v := range config {
+		cfg[k] = v
+	}
+	driver, err := NewMailgunDriver(cfg)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver, got
+}
+
+func TestMailgunDriver_Send_Form(t *testing.T) {
+	driver, got := newTestMailgunDriver(t, map[string]any{
+		"tracking":        true,
+		"tracking_clicks": "htmlonly",
+		"tracking_opens":  false,
+	})
+
+	sendAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	result, err := driver.Send(context.Background(), &Message{
+		From:       "sender@mg.example.com",
+		FromName:   "Sender",
+		To:         []string{"a@example.com", "b@example.com"},
+		Cc:         []string{"cc@example.com"},
+		Bcc:        []string{"bcc@example.com"},
+		ReplyTo:    "reply@example.com",
+		Subject:    "Test",
+		BodyHTML:   `<img src="cid:logo">`,
+		BodyText:   "Hello",
+		Headers:    map[string]string{"X-Campaign": "welcome"},
+		Tags:       map[string]string{"user_id": "42"},
+		Categories: []string{"welcome", "onboarding"},
+		SendAt:     sendAt,
+		Attachments: []Attachment{
+			{Filename: "report.pdf", Content: []byte("%PDF-1.4")},
+			{Filename: "logo.png", Content: []byte("png"), ContentType: "image/png", Inline: true, ContentID: "logo"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessageID != "20240101.1@mg.example.com" || result.Status != "queued" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if got.path != "/v3/mg.example.com/messages" || got.user != "api" || got.pass != "key-123" {
+		t.Errorf("unexpected request: path=%s user=%s pass=%s", got.path, got.user, got.pass)
+	}
+
+	want := map[string][]string{
+		"from":              {`"Sender" <sender@mg.example.com>`},
+		"to":                {"a@example.com", "b@example.com"},
+		"cc":                {"cc@example.com"},
+		"bcc":               {"bcc@example.com"},
+		"subject":           {"Test"},
+		"text":              {"Hello"},
+		"html":              {`<img src="cid:logo">`},
+		"h:Reply-To":        {"reply@example.com"},
+		"h:X-Campaign":      {"welcome"},
+		"v:user_id":         {"42"},
+		"o:tag":             {"welcome", "onboarding"},
+		"o:deliverytime":    {"Wed, 02 Jan 2030 03:04:05 +0000"},
+		"o:tracking":        {"yes"},
+		"o:tracking-clicks": {"htmlonly"},
+		"o:tracking-opens":  {"no"},
+	}
+	for field, values := range want {
+		if strings.Join(got.fields[field], "|") != strings.Join(values, "|") {
+			t.Errorf("%s = %v, want %v", field, got.fields[field], values)
+		}
+	}
+
+	if att := got.files["attachment"]; len(att) != 1 || att[0].filename != "report.pdf" || att[0].contentType != "application/pdf" || att[0].content != "%PDF-1.4" {
+		t.Errorf("unexpected attachment: %+v", att)
+	}
+	if inline := got.files["inline"]; len(inline) != 1 || inline[0].filename != "logo" || inline[0].content != "png" {
+		t.Errorf("unexpected inline file: %+v", inline)
+	}
+}
+
+func TestMailgunDriver_Send_Template(t *testing.T) {
+	driver, got := newTestMailgunDriver(t, nil)
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:         "sender@mg.example.com",
+		To:           []string{"a@example.com"},
+		Subject:      "Welcome",
+		TemplateID:   "welcome",
+		TemplateData: map[string]any{"name": "Alice"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.fields["template"][0] != "welcome" || got.fields["t:variables"][0] != `{"name":"Alice"}` {
+		t.Errorf("unexpected template fields: %v", got.fields)
+	}
+}
+
+func TestMailgunDriver_Send_MIME(t *testing.T) {
+	driver, got := newTestMailgunDriver(t, map[string]any{"mode": "mime"})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:       "sender@mg.example.com",
+		To:         []string{"a@example.com"},
+		Cc:         []string{"cc@example.com"},
+		Bcc:        []string{"bcc@example.com"},
+		Subject:    "Test",
+		BodyText:   "Hello",
+		Categories: []string{"welcome"},
+		Attachments: []Attachment{
+			{Filename: "report.csv", Content: []byte("a,b")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.path != "/v3/mg.example.com/messages.mime" {
+		t.Errorf("unexpected path: %s", got.path)
+	}
+	if strings.Join(got.fields["to"], ",") != "a@example.com,cc@example.com,bcc@example.com" {
+		t.Errorf("unexpected recipients: %v", got.fields["to"])
+	}
+	if got.fields["o:tag"][0] != "welcome" {
+		t.Errorf("unexpected tags: %v", got.fields["o:tag"])
+	}
+
+	message := got.files["message"]
+	if len(message) != 1 {
+		t.Fatalf("expected message file, got %v", got.files)
+	}
+	mime := message[0].content
+	for _, want := range []string{"From: sender@mg.example.com", "Cc: cc@example.com", "Subject: Test", "multipart/mixed", `filename="report.csv"`} {
+		if !strings.Contains(mime, want) {
+			t.Errorf("expected MIME to contain %q:\n%s", want, mime)
+		}
+	}
+	if strings.Contains(mime, "bcc@example.com") {
+		t.Error("expected Bcc to be omitted from MIME headers")
+	}
+
+	// MIME 模式不支持厂商模板
+	_, err = driver.Send(context.Background(), &Message{
+		From:       "sender@mg.example.com",
+		To:         []string{"a@example.com"},
+		Subject:    "Test",
+		TemplateID: "welcome",
+	})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage for template in mime mode, got %v", err)
+	}
+}
+
+func TestMailgunDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `Forbidden`, ErrAuthFailed},
+		{"bad request", http.StatusBadRequest, `{"message":"'to' parameter is not a valid address"}`, ErrInvalidMessage},
+		{"unknown domain", http.StatusNotFound, `{"message":"Domain not found: mg.example.com"}`, ErrDriverConfig},
+		{"rate limited", http.StatusTooManyRequests, `{"message":"Too many requests"}`, ErrRateLimited},
+		{"too large", http.StatusRequestEntityTooLarge, ``, ErrMessageTooLarge},
+		{"server error", http.StatusInternalServerError, `oops`, ErrSendFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			driver, _ := NewMailgunDriver(map[string]any{
+				"api_key":  "key",
+				"domain":   "mg.example.com",
+				"base_url": server.URL,
+			})
+
+			_, err := driver.Send(context.Background(), &Message{
+				From:     "sender@mg.example.com",
+				To:       []string{"a@example.com"},
+				Subject:  "Test",
+				BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistry_Mailgun(t *testing.T) {
+	if !DefaultRegistry.Has(DriverMailgun) {
+		t.Error("expected mailgun driver to be registered")
+	}
+}
@@ -16,6 +16,9 @@ const (
 
 	// MandrillDefaultBaseURL Mandrill API 默认地址
 	MandrillDefaultBaseURL = "https://mandrillapp.com/api/1.0"
+
+	// MandrillDefaultMaxMessageSize Mandrill 单封邮件请求大小上限（25MB）
+	MandrillDefaultMaxMessageSize = 25 << 20
 )
 
 // MandrillConfig Mandrill 驱动配置
@@ -28,6 +31,9 @@ type MandrillConfig struct {
 
 	// Timeout 请求超时时间（可选，默认 30s）
 	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 请求体最大字节数（可选，默认 25MB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
 }
 
 // MandrillDriver Mandrill 邮件驱动
@@ -39,8 +45,9 @@ type MandrillDriver struct {
 // NewMandrillDriver 创建 Mandrill 驱动
 func NewMandrillDriver(config map[string]any) (Driver, error) {
 	cfg := &MandrillConfig{
-		BaseURL: MandrillDefaultBaseURL,
-		Timeout: 30 * time.Second,
+		BaseURL:        MandrillDefaultBaseURL,
+		Timeout:        30 * time.Second,
+		MaxMessageSize: MandrillDefaultMaxMessageSize,
 	}
 
 	// 解析配置
@@ -55,6 +62,9 @@ func NewMandrillDriver(config map[string]any) (Driver, error) {
 			cfg.Timeout = d
 		}
 	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
 
 	driver := &MandrillDriver{
 		config: cfg,
@@ -88,16 +98,27 @@ func (d *MandrillDriver) Send(ctx context.Context, msg *Message) (*Result, error
 	if err := msg.Validate(); err != nil {
 		return nil, err
 	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
 
 	// 构建请求体
-	payload := d.buildPayload(msg)
+	payload, err := d.buildPayload(msg)
+	if err != nil {
+		return nil, err
+	}
 
 	// 发送请求（保留 result 即使有 error，用于部分失败场景）
 	return d.doRequest(ctx, "/messages/send.json", payload)
 }
 
 // buildPayload 构建 Mandrill API 请求体
-func (d *MandrillDriver) buildPayload(msg *Message) map[string]any {
+func (d *MandrillDriver) buildPayload(msg *Message) (map[string]any, error) {
 	// 构建收件人列表
 	recipients := make([]map[string]any, 0, len(msg.To)+len(msg.Cc)+len(msg.Bcc))
 
@@ -136,6 +157,12 @@ func (d *MandrillDriver) buildPayload(msg *Message) map[string]any {
 	if msg.BodyText != "" {
 		message["text"] = msg.BodyText
 	}
+	// 信封发件人: Mandrill 仅支持指定退信域名，由其生成逐收件人的 Return-Path
+	if msg.EnvelopeFrom != "" || msg.VERP {
+		if _, domain, ok := splitAddress(msg.EnvelopeSender()); ok {
+			message["return_path_domain"] = domain
+		}
+	}
 	if msg.ReplyTo != "" {
 		message["headers"] = map[string]string{
 			"Reply-To": msg.ReplyTo,
@@ -154,15 +181,26 @@ func (d *MandrillDriver) buildPayload(msg *Message) map[string]any {
 		message["headers"] = headers
 	}
 
+	// 消息标签: Mandrill 的键值对标签为 metadata
+	if len(msg.Tags) > 0 {
+		message["metadata"] = msg.Tags
+	}
+
 	// 附件
 	if len(msg.Attachments) > 0 {
 		attachments := make([]map[string]any, 0, len(msg.Attachments))
 		images := make([]map[string]any, 0)
 
-		for _, att := range msg.Attachments {
+		for i := range msg.Attachments {
+			att := &msg.Attachments[i]
+			// API 请求需要完整内容，流式附件在此读取
+			content, err := att.ReadAll()
+			if err != nil {
+				return nil, err
+			}
 			item := map[string]any{
 				"name":    att.Filename,
-				"content": base64.StdEncoding.EncodeToString(att.Content),
+				"content": base64.StdEncoding.EncodeToString(content),
 			}
 			if att.ContentType != "" {
 				item["type"] = att.ContentType
@@ -187,10 +225,15 @@ func (d *MandrillDriver) buildPayload(msg *Message) map[string]any {
 		}
 	}
 
-	return map[string]any{
+	payload := map[string]any{
 		"key":     d.config.APIKey,
 		"message": message,
 	}
+	// 定时发送（UTC，格式 YYYY-MM-DD HH:MM:SS）
+	if !msg.SendAt.IsZero() {
+		payload["send_at"] = msg.SendAt.UTC().Format(time.DateTime)
+	}
+	return payload, nil
 }
 
 // doRequest 发送 HTTP 请求
@@ -202,6 +245,11 @@ func (d *MandrillDriver) doRequest(ctx context.Context, endpoint string, payload
 		return nil, ErrSendFailed.Wrap(err).WithMsg("序列化请求失败")
 	}
 
+	// 发送前检查大小限制
+	if max := d.config.MaxMessageSize; max > 0 && int64(len(body)) > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", len(body), max)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
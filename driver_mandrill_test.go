@@ -2,10 +2,14 @@ package email
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewMandrillDriver(t *testing.T) {
@@ -527,3 +531,204 @@ func TestMandrillDriver_Send_InvalidJSON(t *testing.T) {
 		t.Error("expected error for invalid JSON")
 	}
 }
+
+func TestMandrillDriver_Send_WithEnvelopeFrom(t *testing.T) {
+	var receivedPayload map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedPayload)
+
+		response := []map[string]any{
+			{"_id": "test", "status": "sent"},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	driver, _ := NewMandrillDriver(map[string]any{
+		"api_key":  "test",
+		"base_url": server.URL,
+	})
+
+	msg := &Message{
+		From:         "noreply@example.com",
+		EnvelopeFrom: "bounces@mail.example.com",
+		To:           []string{"to@example.com"},
+		Subject:      "Test",
+		BodyHTML:     "Hello",
+	}
+
+	_, err := driver.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message := receivedPayload["message"].(map[string]any)
+	if message["return_path_domain"] != "mail.example.com" {
+		t.Errorf("expected return_path_domain, got %v", message["return_path_domain"])
+	}
+	if message["from_email"] != "noreply@example.com" {
+		t.Errorf("expected from_email to be unchanged, got %v", message["from_email"])
+	}
+}
+
+func TestMandrillDriver_Send_MaxMessageSize(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		json.NewEncoder(w).Encode([]map[string]any{{"_id": "abc", "status": "sent"}})
+	}))
+	defer server.Close()
+
+	driver, err := NewMandrillDriver(map[string]any{
+		"api_key":          "test-key",
+		"base_url":         server.URL,
+		"max_message_size": "1KB",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:    "sender@example.com",
+		To:      []string{"to@example.com"},
+		Subject: "Test",
+		Attachments: []Attachment{
+			{Filename: "big.bin", Content: make([]byte, 2048)},
+		},
+		BodyHTML: "Hello",
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+	if called {
+		t.Error("expected request not to be sent")
+	}
+}
+
+func TestMandrillDriver_Send_WithReaderAttachment(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode([]map[string]any{{"_id": "abc", "status": "sent"}})
+	}))
+	defer server.Close()
+
+	driver, _ := NewMandrillDriver(map[string]any{
+		"api_key":  "test-key",
+		"base_url": server.URL,
+	})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyHTML: "Hello",
+		Attachments: []Attachment{
+			{Filename: "data.csv", Reader: strings.NewReader("a,b,c"), ContentType: "text/csv"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message := payload["message"].(map[string]any)
+	attachments := message["attachments"].([]any)
+	att := attachments[0].(map[string]any)
+	if att["content"] != base64.StdEncoding.EncodeToString([]byte("a,b,c")) {
+		t.Errorf("unexpected attachment content: %v", att["content"])
+	}
+}
+
+func TestMandrillDriver_Send_DetectsAttachmentType(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode([]map[string]any{{"_id": "abc", "status": "sent"}})
+	}))
+	defer server.Close()
+
+	driver, _ := NewMandrillDriver(map[string]any{
+		"api_key":  "test-key",
+		"base_url": server.URL,
+	})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyHTML: "Hello",
+		Attachments: []Attachment{
+			{Filename: "report.pdf", Content: []byte("%PDF-1.4")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message := payload["message"].(map[string]any)
+	att := message["attachments"].([]any)[0].(map[string]any)
+	if att["type"] != "application/pdf" {
+		t.Errorf("expected detected type application/pdf, got %v", att["type"])
+	}
+}
+
+func TestMandrillDriver_Send_WithTags(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode([]map[string]any{{"_id": "abc", "status": "sent"}})
+	}))
+	defer server.Close()
+
+	driver, _ := NewMandrillDriver(map[string]any{
+		"api_key":  "test-key",
+		"base_url": server.URL,
+	})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyHTML: "Hello",
+		Tags:     map[string]string{"campaign": "welcome"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message := payload["message"].(map[string]any)
+	metadata := message["metadata"].(map[string]any)
+	if metadata["campaign"] != "welcome" {
+		t.Errorf("expected tags as metadata, got %v", message["metadata"])
+	}
+}
+
+func TestMandrillDriver_Send_SendAt(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode([]map[string]any{{"_id": "abc", "status": "scheduled"}})
+	}))
+	defer server.Close()
+
+	driver, _ := NewMandrillDriver(map[string]any{
+		"api_key":  "test-key",
+		"base_url": server.URL,
+	})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyHTML: "Hello",
+		SendAt:   time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payload["send_at"] != "2030-01-02 03:04:05" {
+		t.Errorf("unexpected send_at: %v", payload["send_at"])
+	}
+}
@@ -0,0 +1,384 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverPostmark Postmark 驱动名称
+	DriverPostmark = "postmark"
+
+	// PostmarkDefaultBaseURL Postmark API 默认地址
+	PostmarkDefaultBaseURL = "https://api.postmarkapp.com"
+
+	// PostmarkStreamOutbound 默认事务类消息流
+	PostmarkStreamOutbound = "outbound"
+
+	// PostmarkStreamBroadcasts 默认广播类消息流
+	PostmarkStreamBroadcasts = "broadcasts"
+
+	// PostmarkStreamHeader 按消息指定消息流的头（与 Postmark SMTP 约定一致），发送时从自定义头中移除
+	PostmarkStreamHeader = "X-PM-Message-Stream"
+
+	// PostmarkDefaultMaxMessageSize Postmark 单封邮件大小上限（10MB）
+	PostmarkDefaultMaxMessageSize = 10 << 20
+
+	// PostmarkMaxBatchSize 单次批量发送的最大消息数
+	PostmarkMaxBatchSize = 500
+)
+
+// PostmarkConfig Postmark 驱动配置
+type PostmarkConfig struct {
+	// ServerToken 服务器 API Token
+	ServerToken string `mapstructure:"server_token"`
+
+	// BaseURL API 基础地址（可选，默认 https://api.postmarkapp.com）
+	BaseURL string `mapstructure:"base_url"`
+
+	// MessageStream 默认消息流（可选，默认 outbound）
+	MessageStream string `mapstructure:"message_stream"`
+
+	// TrackOpens 是否跟踪打开（可选）
+	TrackOpens bool `mapstructure:"track_opens"`
+
+	// TrackLinks 链接跟踪（可选，None/HtmlAndText/HtmlOnly/TextOnly）
+	TrackLinks string `mapstructure:"track_links"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 单封邮件最大字节数（可选，默认 10MB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// PostmarkDriver Postmark 邮件驱动
+type PostmarkDriver struct {
+	config *PostmarkConfig
+	client *http.Client
+}
+
+// NewPostmarkDriver 创建 Postmark 驱动
+func NewPostmarkDriver(config map[string]any) (Driver, error) {
+	cfg := &PostmarkConfig{
+		BaseURL:        PostmarkDefaultBaseURL,
+		MessageStream:  PostmarkStreamOutbound,
+		Timeout:        30 * time.Second,
+		MaxMessageSize: PostmarkDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if token, ok := config["server_token"].(string); ok {
+		cfg.ServerToken = token
+	}
+	if baseURL, ok := config["base_url"].(string); ok && baseURL != "" {
+		cfg.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if stream, ok := config["message_stream"].(string); ok && stream != "" {
+		cfg.MessageStream = stream
+	}
+	if trackOpens, ok := config["track_opens"].(bool); ok {
+		cfg.TrackOpens = trackOpens
+	}
+	if trackLinks, ok := config["track_links"].(string); ok {
+		cfg.TrackLinks = trackLinks
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	driver := &PostmarkDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// Name 驱动名称
+func (d *PostmarkDriver) Name() string {
+	return DriverPostmark
+}
+
+// Validate 验证配置
+func (d *PostmarkDriver) Validate() error {
+	if d.config.ServerToken == "" {
+		return ErrDriverConfig.WithMsg("Postmark Server Token 不能为空")
+	}
+	switch d.config.TrackLinks {
+	case "", "None", "HtmlAndText", "HtmlOnly", "TextOnly":
+	default:
+		return ErrDriverConfig.WithMsgf("Postmark TrackLinks 无效: %s", d.config.TrackLinks)
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("Postmark MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+func (d *PostmarkDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	payload, err := d.prepare(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := d.doRequest(ctx, "/email", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var out postmarkResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("解析响应失败")
+	}
+	return out.result()
+}
+
+// SendBatch 通过 /email/batch 一次提交多封邮件（最多 500 封）
+// 返回的结果与 msgs 一一对应；部分邮件被拒绝时对应结果 Success 为 false，
+// 并返回合并后的错误（可通过 errors.Is 判断错误类型）
+func (d *PostmarkDriver) SendBatch(ctx context.Context, msgs []*Message) ([]*Result, error) {
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	if len(msgs) > PostmarkMaxBatchSize {
+		return nil, ErrInvalidMessage.WithMsgf("Postmark 批量发送最多 %d 封，实际 %d 封", PostmarkMaxBatchSize, len(msgs))
+	}
+
+	payloads := make([][]byte, 0, len(msgs))
+	for _, msg := range msgs {
+		payload, err := d.prepare(msg)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload)
+	}
+
+	// 直接拼接各封邮件已序列化的请求体
+	batch := append([]byte{'['}, bytes.Join(payloads, []byte{','})...)
+	batch = append(batch, ']')
+
+	body, err := d.doRequest(ctx, "/email/batch", batch)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []postmarkResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("解析响应失败")
+	}
+	if len(out) != len(msgs) {
+		return nil, ErrSendFailed.WithMsgf("Postmark 批量响应数量不匹配: 提交 %d 封，返回 %d 条", len(msgs), len(out))
+	}
+
+	results := make([]*Result, len(out))
+	var errs []error
+	for i := range out {
+		result, err := out[i].result()
+		if err != nil {
+			results[i] = &Result{MessageID: out[i].MessageID, Status: "failed"}
+			errs = append(errs, fmt.Errorf("第 %d 封: %w", i+1, err))
+			continue
+		}
+		results[i] = result
+	}
+	return results, errors.Join(errs...)
+}
+
+// prepare 校验消息并构建序列化后的请求体，单封请求体超过大小限制时拒绝
+func (d *PostmarkDriver) prepare(msg *Message) ([]byte, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireImmediate(); err != nil {
+		return nil, err
+	}
+	if len(msg.Categories) > 1 {
+		return nil, ErrInvalidMessage.WithMsg("Postmark 每封邮件只支持一个分类（Tag）")
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+
+	payload, err := d.buildPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("序列化请求失败")
+	}
+	if max := d.config.MaxMessageSize; max > 0 && int64(len(data)) > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", len(data), max)
+	}
+	return data, nil
+}
+
+// buildPayload 构建 Postmark 邮件请求体
+func (d *PostmarkDriver) buildPayload(msg *Message) (map[string]any, error) {
+	payload := map[string]any{
+		"From":          displayAddress(msg.FromName, msg.From),
+		"To":            strings.Join(headerAddresses(msg.To), ","),
+		"Subject":       msg.Subject,
+		"MessageStream": d.config.MessageStream,
+	}
+	if len(msg.Cc) > 0 {
+		payload["Cc"] = strings.Join(headerAddresses(msg.Cc), ",")
+	}
+	if len(msg.Bcc) > 0 {
+		payload["Bcc"] = strings.Join(headerAddresses(msg.Bcc), ",")
+	}
+	if msg.ReplyTo != "" {
+		payload["ReplyTo"] = headerAddress(msg.ReplyTo)
+	}
+	if msg.BodyHTML != "" {
+		payload["HtmlBody"] = msg.BodyHTML
+	}
+	if msg.BodyText != "" {
+		payload["TextBody"] = msg.BodyText
+	}
+
+	// 自定义头，消息流头用于选择 MessageStream
+	var headers []map[string]string
+	for _, name := range sortedKeys(msg.Headers) {
+		if strings.EqualFold(name, PostmarkStreamHeader) {
+			if stream := msg.Headers[name]; stream != "" {
+				payload["MessageStream"] = stream
+			}
+			continue
+		}
+		headers = append(headers, map[string]string{"Name": name, "Value": msg.Headers[name]})
+	}
+	if len(headers) > 0 {
+		payload["Headers"] = headers
+	}
+
+	// 标签与元数据: Postmark 每封邮件只支持一个 Tag（多个分类在 prepare 中拒绝）
+	if len(msg.Categories) > 0 {
+		payload["Tag"] = msg.Categories[0]
+	}
+	if len(msg.Tags) > 0 {
+		payload["Metadata"] = msg.Tags
+	}
+
+	// 跟踪
+	if d.config.TrackOpens {
+		payload["TrackOpens"] = true
+	}
+	if d.config.TrackLinks != "" {
+		payload["TrackLinks"] = d.config.TrackLinks
+	}
+
+	// 附件
+	if len(msg.Attachments) > 0 {
+		attachments := make([]map[string]string, 0, len(msg.Attachments))
+		for i := range msg.Attachments {
+			att := &msg.Attachments[i]
+			// API 请求需要完整内容，流式附件在此读取
+			data, err := att.ReadAll()
+			if err != nil {
+				return nil, err
+			}
+			item := map[string]string{
+				"Name":        att.Filename,
+				"Content":     base64.StdEncoding.EncodeToString(data),
+				"ContentType": att.ContentType,
+			}
+			if att.Inline && att.ContentID != "" {
+				// 内联图片
+				item["ContentID"] = "cid:" + att.ContentID
+			}
+			attachments = append(attachments, item)
+		}
+		payload["Attachments"] = attachments
+	}
+
+	return payload, nil
+}
+
+// doRequest 发送已序列化的请求体，返回成功响应体
+func (d *PostmarkDriver) doRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.config.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Postmark-Server-Token", d.config.ServerToken)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var out postmarkResponse
+		pmErr := &PostmarkError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(respBody))}
+		if err := json.Unmarshal(respBody, &out); err == nil && out.Message != "" {
+			pmErr.ErrorCode = out.ErrorCode
+			pmErr.Message = out.Message
+		}
+		return nil, postmarkErrorCode(pmErr).Wrap(pmErr).WithMsgf("Postmark API 错误 (%d): %s", pmErr.ErrorCode, pmErr.Message)
+	}
+	return respBody, nil
+}
+
+// postmarkResponse 单封邮件的发送响应
+type postmarkResponse struct {
+	MessageID string `json:"MessageID"`
+	ErrorCode int    `json:"ErrorCode"`
+	Message   string `json:"Message"`
+}
+
+// result 转换为发送结果，ErrorCode 非 0 时返回错误
+func (r *postmarkResponse) result() (*Result, error) {
+	if r.ErrorCode != 0 {
+		pmErr := &PostmarkError{StatusCode: http.StatusOK, ErrorCode: r.ErrorCode, Message: r.Message}
+		return nil, postmarkErrorCode(pmErr).Wrap(pmErr).WithMsgf("Postmark API 错误 (%d): %s", pmErr.ErrorCode, pmErr.Message)
+	}
+	return &Result{
+		MessageID: r.MessageID,
+		Status:    "sent",
+		Success:   true,
+	}, nil
+}
+
+func init() {
+	// 注册 Postmark 驱动到默认注册表
+	RegisterDriver(DriverPostmark, NewPostmarkDriver)
+}
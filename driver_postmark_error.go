@@ -0,0 +1,55 @@
+package email
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/KOMKZ/go-yogan-framework/errcode"
+)
+
+// PostmarkError Postmark API 返回的错误，可通过 errors.As 获取
+type PostmarkError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+
+	// ErrorCode Postmark 错误码（如 406 收件人已停用）
+	ErrorCode int
+
+	// Message 错误描述
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *PostmarkError) Error() string {
+	return fmt.Sprintf("postmark %d (code %d): %s", e.StatusCode, e.ErrorCode, e.Message)
+}
+
+// postmarkErrorCode 将 Postmark 错误码映射为组件错误码
+// 见 https://postmarkapp.com/developer/api/overview#error-codes
+func postmarkErrorCode(e *PostmarkError) *errcode.AppError {
+	switch e.ErrorCode {
+	case 10:
+		// Server Token 无效
+		return ErrAuthFailed
+	case 300, 402, 403, 409:
+		// 请求内容无效
+		return ErrInvalidMessage
+	case 400, 401, 1235:
+		// 发件人签名不存在/未确认，消息流不存在
+		return ErrDriverConfig
+	case 406:
+		// 收件人已停用（硬退信、投诉或手动禁用）
+		return ErrInvalidRecipient
+	}
+
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuthFailed
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusRequestEntityTooLarge:
+		return ErrMessageTooLarge
+	default:
+		return ErrSendFailed
+	}
+}
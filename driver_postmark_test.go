@@ -0,0 +1,244 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewPostmarkDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantErr bool
+	}{
+		{"valid config", map[string]any{"server_token": "token"}, false},
+		{"with tracking", map[string]any{"server_token": "token", "track_opens": true, "track_links": "HtmlOnly"}, false},
+		{"missing token", map[string]any{}, true},
+		{"invalid track links", map[string]any{"server_token": "token", "track_links": "always"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPostmarkDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPostmarkDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestPostmarkDriver 创建指向本地服务器的 Postmark 驱动
+func newTestPostmarkDriver(t *testing.T, handler http.HandlerFunc) *PostmarkDriver {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	driver, err := NewPostmarkDriver(map[string]any{
+		"server_token": "server-token",
+		"base_url":     server.URL,
+		"track_opens":  true,
+		"track_links":  "HtmlAndText",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver.(*PostmarkDriver)
+}
+
+func TestPostmarkDriver_Send_Success(t *testing.T) {
+	var payload map[string]any
+	var token, path string
+	driver := newTestPostmarkDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Postmark-Server-Token")
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`{"To":"a@example.com","MessageID":"pm-1","ErrorCode":0,"Message":"OK"}`))
+	})
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:       "sender@example.com",
+		FromName:   "Sender",
+		To:         []string{"a@example.com", "b@example.com"},
+		Bcc:        []string{"bcc@example.com"},
+		ReplyTo:    "reply@example.com",
+		Subject:    "Test",
+		BodyHTML:   `<img src="cid:logo">`,
+		BodyText:   "Hello",
+		Headers:    map[string]string{"X-Campaign": "welcome", PostmarkStreamHeader: PostmarkStreamBroadcasts},
+		Tags:       map[string]string{"user_id": "42"},
+		Categories: []string{"welcome"},
+		Attachments: []Attachment{
+			{Filename: "report.pdf", Content: []byte("%PDF-1.4")},
+			{Filename: "logo.png", Content: []byte("png"), ContentType: "image/png", Inline: true, ContentID: "logo"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessageID != "pm-1" || !result.Success {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if token != "server-token" || path != "/email" {
+		t.Errorf("unexpected request: token=%q path=%q", token, path)
+	}
+
+	want := map[string]any{
+		"From":          `"Sender" <sender@example.com>`,
+		"To":            "a@example.com,b@example.com",
+		"Bcc":           "bcc@example.com",
+		"ReplyTo":       "reply@example.com",
+		"Tag":           "welcome",
+		"MessageStream": PostmarkStreamBroadcasts,
+		"TrackOpens":    true,
+		"TrackLinks":    "HtmlAndText",
+	}
+	for k, v := range want {
+		if payload[k] != v {
+			t.Errorf("%s = %v, want %v", k, payload[k], v)
+		}
+	}
+	if payload["Metadata"].(map[string]any)["user_id"] != "42" {
+		t.Errorf("unexpected metadata: %v", payload["Metadata"])
+	}
+	headers := payload["Headers"].([]any)
+	if len(headers) != 1 || headers[0].(map[string]any)["Name"] != "X-Campaign" {
+		t.Errorf("expected stream header to be removed, got %v", headers)
+	}
+
+	attachments := payload["Attachments"].([]any)
+	if pdf := attachments[0].(map[string]any); pdf["ContentType"] != "application/pdf" || pdf["ContentID"] != nil {
+		t.Errorf("unexpected attachment: %v", pdf)
+	}
+	if logo := attachments[1].(map[string]any); logo["ContentID"] != "cid:logo" {
+		t.Errorf("unexpected inline attachment: %v", logo)
+	}
+}
+
+func TestPostmarkDriver_Send_DefaultStream(t *testing.T) {
+	var payload map[string]any
+	driver := newTestPostmarkDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`{"MessageID":"pm-1","ErrorCode":0,"Message":"OK"}`))
+	})
+
+	if _, err := driver.Send(context.Background(), &Message{
+		From: "sender@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload["MessageStream"] != PostmarkStreamOutbound {
+		t.Errorf("unexpected stream: %v", payload["MessageStream"])
+	}
+}
+
+func TestPostmarkDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"inactive recipient", http.StatusUnprocessableEntity, `{"ErrorCode":406,"Message":"You tried to send to recipient(s) that have been marked as inactive."}`, ErrInvalidRecipient},
+		{"bad token", http.StatusUnauthorized, `{"ErrorCode":10,"Message":"No Account or Server API tokens were supplied in the HTTP headers."}`, ErrAuthFailed},
+		{"sender signature", http.StatusUnprocessableEntity, `{"ErrorCode":400,"Message":"The 'From' address you supplied is not a Sender Signature on your account."}`, ErrDriverConfig},
+		{"invalid request", http.StatusUnprocessableEntity, `{"ErrorCode":300,"Message":"Invalid email request"}`, ErrInvalidMessage},
+		{"account inactive", http.StatusUnprocessableEntity, `{"ErrorCode":412,"Message":"Account pending approval"}`, ErrSendFailed},
+		{"rate limited", http.StatusTooManyRequests, `Too many requests`, ErrRateLimited},
+		{"server error", http.StatusInternalServerError, `oops`, ErrSendFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := newTestPostmarkDriver(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := driver.Send(context.Background(), &Message{
+				From: "sender@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var pmErr *PostmarkError
+			if !errors.As(err, &pmErr) || pmErr.StatusCode != tt.status {
+				t.Errorf("expected PostmarkError with status %d, got %v", tt.status, pmErr)
+			}
+		})
+	}
+}
+
+func TestPostmarkDriver_SendBatch(t *testing.T) {
+	var payloads []map[string]any
+	var path string
+	driver := newTestPostmarkDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payloads)
+		w.Write([]byte(`[
+			{"To":"a@example.com","MessageID":"pm-1","ErrorCode":0,"Message":"OK"},
+			{"ErrorCode":406,"Message":"You tried to send to recipient(s) that have been marked as inactive."}
+		]`))
+	})
+
+	results, err := driver.SendBatch(context.Background(), []*Message{
+		{From: "sender@example.com", To: []string{"a@example.com"}, Subject: "A", BodyText: "Hello"},
+		{From: "sender@example.com", To: []string{"inactive@example.com"}, Subject: "B", BodyText: "Hello"},
+	})
+	if path != "/email/batch" || len(payloads) != 2 || payloads[1]["Subject"] != "B" {
+		t.Errorf("unexpected batch request: path=%s payloads=%v", path, payloads)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if !results[0].Success || results[0].MessageID != "pm-1" {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+	if results[1].Success || results[1].Status != "failed" {
+		t.Errorf("unexpected second result: %+v", results[1])
+	}
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Errorf("expected ErrInvalidRecipient, got %v", err)
+	}
+}
+
+func TestPostmarkDriver_SendBatch_Invalid(t *testing.T) {
+	driver := newTestPostmarkDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	_, err := driver.SendBatch(context.Background(), []*Message{
+		{From: "sender@example.com", To: []string{"a@example.com"}, Subject: "A", BodyText: "Hello"},
+		{From: "sender@example.com", To: []string{"b@example.com"}},
+	})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage, got %v", err)
+	}
+}
+
+func TestPostmarkDriver_Send_MultipleCategories(t *testing.T) {
+	driver := newTestPostmarkDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	// Postmark 只有一个 Tag 字段，多余的分类不能被静默丢弃
+	_, err := driver.Send(context.Background(), &Message{
+		From:       "sender@example.com",
+		To:         []string{"a@example.com"},
+		Subject:    "Test",
+		BodyText:   "Hello",
+		Categories: []string{"welcome", "newsletter"},
+	})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage, got %v", err)
+	}
+}
+
+func TestDefaultRegistry_Postmark(t *testing.T) {
+	if !DefaultRegistry.Has(DriverPostmark) {
+		t.Error("expected postmark driver to be registered")
+	}
+}
@@ -0,0 +1,303 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverSendGrid SendGrid 驱动名称
+	DriverSendGrid = "sendgrid"
+
+	// SendGridDefaultBaseURL SendGrid API 默认地址
+	SendGridDefaultBaseURL = "https://api.sendgrid.com"
+
+	// SendGridDefaultMaxMessageSize SendGrid 单封邮件大小上限（30MB）
+	SendGridDefaultMaxMessageSize = 30 << 20
+)
+
+// SendGridConfig SendGrid 驱动配置
+type SendGridConfig struct {
+	// APIKey SendGrid API Key
+	APIKey string `mapstructure:"api_key"`
+
+	// BaseURL API 基础地址（可选，默认 https://api.sendgrid.com）
+	BaseURL string `mapstructure:"base_url"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 请求体最大字节数（可选，默认 30MB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// SendGridDriver SendGrid 邮件驱动（v3 Mail Send）
+type SendGridDriver struct {
+	config *SendGridConfig
+	client *http.Client
+}
+
+// NewSendGridDriver 创建 SendGrid 驱动
+func NewSendGridDriver(config map[string]any) (Driver, error) {
+	cfg := &SendGridConfig{
+		BaseURL:        SendGridDefaultBaseURL,
+		Timeout:        30 * time.Second,
+		MaxMessageSize: SendGridDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if apiKey, ok := config["api_key"].(string); ok {
+		cfg.APIKey = apiKey
+	}
+	if baseURL, ok := config["base_url"].(string); ok && baseURL != "" {
+		cfg.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	driver := &SendGridDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// Name 驱动名称
+func (d *SendGridDriver) Name() string {
+	return DriverSendGrid
+}
+
+// Validate 验证配置
+func (d *SendGridDriver) Validate() error {
+	if d.config.APIKey == "" {
+		return ErrDriverConfig.WithMsg("SendGrid API Key 不能为空")
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("SendGrid MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+func (d *SendGridDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+
+	// 构建请求体
+	payload, err := d.buildPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.doRequest(ctx, payload)
+}
+
+// sendGridAddress SendGrid 地址对象
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridAddresses 转换地址列表
+func sendGridAddresses(addrs []string) []sendGridAddress {
+	out := make([]sendGridAddress, 0, len(addrs))
+	for _, addr := range addrs {
+		out = append(out, sendGridAddress{Email: addr})
+	}
+	return out
+}
+
+// buildPayload 构建 v3 Mail Send 请求体
+// 收件人、自定义参数与定时发送放在同一个 personalization 中
+func (d *SendGridDriver) buildPayload(msg *Message) (map[string]any, error) {
+	personalization := map[string]any{
+		"to": sendGridAddresses(msg.To),
+	}
+	if len(msg.Cc) > 0 {
+		personalization["cc"] = sendGridAddresses(msg.Cc)
+	}
+	if len(msg.Bcc) > 0 {
+		personalization["bcc"] = sendGridAddresses(msg.Bcc)
+	}
+	if len(msg.Tags) > 0 {
+		personalization["custom_args"] = msg.Tags
+	}
+	if !msg.SendAt.IsZero() {
+		personalization["send_at"] = msg.SendAt.Unix()
+	}
+
+	payload := map[string]any{
+		"personalizations": []map[string]any{personalization},
+		"from":             sendGridAddress{Email: msg.From, Name: msg.FromName},
+		"subject":          msg.Subject,
+	}
+	if msg.ReplyTo != "" {
+		payload["reply_to"] = sendGridAddress{Email: msg.ReplyTo}
+	}
+
+	// 正文（text/plain 必须在 text/html 之前）
+	content := make([]map[string]string, 0, 2)
+	if msg.BodyText != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": msg.BodyText})
+	}
+	if msg.BodyHTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": msg.BodyHTML})
+	}
+	payload["content"] = content
+
+	// 自定义头
+	if len(msg.Headers) > 0 {
+		payload["headers"] = msg.Headers
+	}
+	if len(msg.Categories) > 0 {
+		payload["categories"] = msg.Categories
+	}
+
+	// 附件
+	if len(msg.Attachments) > 0 {
+		attachments := make([]map[string]string, 0, len(msg.Attachments))
+		for i := range msg.Attachments {
+			att := &msg.Attachments[i]
+			// API 请求需要完整内容，流式附件在此读取
+			data, err := att.ReadAll()
+			if err != nil {
+				return nil, err
+			}
+			item := map[string]string{
+				"content":     base64.StdEncoding.EncodeToString(data),
+				"filename":    att.Filename,
+				"disposition": "attachment",
+			}
+			if att.ContentType != "" {
+				item["type"] = att.ContentType
+			}
+			if att.Inline {
+				// 内联图片
+				item["disposition"] = "inline"
+				if att.ContentID != "" {
+					item["content_id"] = att.ContentID
+				}
+			}
+			attachments = append(attachments, item)
+		}
+		payload["attachments"] = attachments
+	}
+
+	return payload, nil
+}
+
+// doRequest 发送 HTTP 请求
+func (d *SendGridDriver) doRequest(ctx context.Context, payload map[string]any) (*Result, error) {
+	url := d.config.BaseURL + "/v3/mail/send"
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("序列化请求失败")
+	}
+
+	// 发送前检查大小限制
+	if max := d.config.MaxMessageSize; max > 0 && int64(len(body)) > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", len(body), max)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+d.config.APIKey)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	// 解析响应
+	return d.parseResponse(resp, respBody)
+}
+
+// parseResponse 解析 SendGrid 响应
+// 成功时返回 202 与空响应体，消息 ID 在 X-Message-Id 头中
+func (d *SendGridDriver) parseResponse(resp *http.Response, body []byte) (*Result, error) {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return &Result{
+			MessageID: resp.Header.Get("X-Message-Id"),
+			Status:    "queued",
+			Success:   true,
+		}, nil
+	}
+
+	var errResp struct {
+		Errors []struct {
+			Message string `json:"message"`
+			Field   string `json:"field"`
+		} `json:"errors"`
+	}
+	reason := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &errResp); err == nil && len(errResp.Errors) > 0 {
+		reasons := make([]string, 0, len(errResp.Errors))
+		for _, e := range errResp.Errors {
+			if e.Field != "" {
+				reasons = append(reasons, e.Field+": "+e.Message)
+			} else {
+				reasons = append(reasons, e.Message)
+			}
+		}
+		reason = strings.Join(reasons, "; ")
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrAuthFailed.WithMsgf("SendGrid 认证失败: %s", reason)
+	case http.StatusTooManyRequests:
+		return nil, ErrRateLimited.WithMsgf("SendGrid 限流: %s", reason)
+	case http.StatusRequestEntityTooLarge:
+		return nil, ErrMessageTooLarge.WithMsgf("SendGrid 拒绝: %s", reason)
+	case http.StatusBadRequest:
+		return nil, ErrInvalidMessage.WithMsgf("SendGrid 请求无效: %s", reason)
+	default:
+		return nil, ErrSendFailed.WithMsgf("SendGrid API 错误 (%d): %s", resp.StatusCode, reason)
+	}
+}
+
+func init() {
+	// 注册 SendGrid 驱动到默认注册表
+	RegisterDriver(DriverSendGrid, NewSendGridDriver)
+}
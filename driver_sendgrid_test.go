@@ -0,0 +1,154 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewSendGridDriver(t *testing.T) {
+	if _, err := NewSendGridDriver(map[string]any{"api_key": "SG.key"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewSendGridDriver(map[string]any{}); !errors.Is(err, ErrDriverConfig) {
+		t.Errorf("expected ErrDriverConfig for missing api key, got %v", err)
+	}
+}
+
+func TestSendGridDriver_Send_Success(t *testing.T) {
+	var payload map[string]any
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("X-Message-Id", "sg-123")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	driver, _ := NewSendGridDriver(map[string]any{
+		"api_key":  "SG.key",
+		"base_url": server.URL,
+	})
+
+	sendAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	result, err := driver.Send(context.Background(), &Message{
+		From:       "sender@example.com",
+		FromName:   "Sender",
+		To:         []string{"to@example.com"},
+		Cc:         []string{"cc@example.com"},
+		Bcc:        []string{"bcc@example.com"},
+		ReplyTo:    "reply@example.com",
+		Subject:    "Test",
+		BodyHTML:   "<h1>Hello</h1>",
+		BodyText:   "Hello",
+		Headers:    map[string]string{"X-Campaign": "welcome"},
+		Tags:       map[string]string{"user_id": "42"},
+		Categories: []string{"welcome", "onboarding"},
+		SendAt:     sendAt,
+		Attachments: []Attachment{
+			{Filename: "report.pdf", Content: []byte("%PDF-1.4")},
+			{Filename: "logo.png", Content: []byte("png"), ContentType: "image/png", Inline: true, ContentID: "logo"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessageID != "sg-123" || result.Status != "queued" || !result.Success {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if auth != "Bearer SG.key" || path != "/v3/mail/send" {
+		t.Errorf("unexpected request: auth=%q path=%q", auth, path)
+	}
+
+	personalization := payload["personalizations"].([]any)[0].(map[string]any)
+	if len(personalization["to"].([]any)) != 1 || len(personalization["cc"].([]any)) != 1 || len(personalization["bcc"].([]any)) != 1 {
+		t.Errorf("unexpected recipients: %v", personalization)
+	}
+	if personalization["custom_args"].(map[string]any)["user_id"] != "42" {
+		t.Errorf("unexpected custom args: %v", personalization["custom_args"])
+	}
+	if int64(personalization["send_at"].(float64)) != sendAt.Unix() {
+		t.Errorf("unexpected send_at: %v", personalization["send_at"])
+	}
+
+	from := payload["from"].(map[string]any)
+	if from["email"] != "sender@example.com" || from["name"] != "Sender" {
+		t.Errorf("unexpected from: %v", from)
+	}
+	if payload["reply_to"].(map[string]any)["email"] != "reply@example.com" {
+		t.Errorf("unexpected reply_to: %v", payload["reply_to"])
+	}
+	content := payload["content"].([]any)
+	if content[0].(map[string]any)["type"] != "text/plain" || content[1].(map[string]any)["type"] != "text/html" {
+		t.Errorf("expected text/plain before text/html, got %v", content)
+	}
+	if payload["headers"].(map[string]any)["X-Campaign"] != "welcome" {
+		t.Errorf("unexpected headers: %v", payload["headers"])
+	}
+	if len(payload["categories"].([]any)) != 2 {
+		t.Errorf("unexpected categories: %v", payload["categories"])
+	}
+
+	attachments := payload["attachments"].([]any)
+	pdf := attachments[0].(map[string]any)
+	if pdf["disposition"] != "attachment" || pdf["type"] != "application/pdf" || pdf["content"] != base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")) {
+		t.Errorf("unexpected attachment: %v", pdf)
+	}
+	logo := attachments[1].(map[string]any)
+	if logo["disposition"] != "inline" || logo["content_id"] != "logo" {
+		t.Errorf("unexpected inline attachment: %v", logo)
+	}
+}
+
+func TestSendGridDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"errors":[{"message":"The provided authorization grant is invalid"}]}`, ErrAuthFailed},
+		{"rate limited", http.StatusTooManyRequests, `{"errors":[{"message":"too many requests"}]}`, ErrRateLimited},
+		{"bad request", http.StatusBadRequest, `{"errors":[{"message":"Invalid email","field":"personalizations.0.to.0.email"}]}`, ErrInvalidMessage},
+		{"too large", http.StatusRequestEntityTooLarge, ``, ErrMessageTooLarge},
+		{"server error", http.StatusInternalServerError, `oops`, ErrSendFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			driver, _ := NewSendGridDriver(map[string]any{
+				"api_key":  "SG.key",
+				"base_url": server.URL,
+			})
+
+			_, err := driver.Send(context.Background(), &Message{
+				From:     "sender@example.com",
+				To:       []string{"to@example.com"},
+				Subject:  "Test",
+				BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistry_SendGrid(t *testing.T) {
+	if !DefaultRegistry.Has(DriverSendGrid) {
+		t.Error("expected sendgrid driver to be registered")
+	}
+}
@@ -0,0 +1,260 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverSendmail sendmail 驱动名称
+	DriverSendmail = "sendmail"
+
+	// SendmailDefaultPath sendmail 默认路径
+	SendmailDefaultPath = "/usr/sbin/sendmail"
+
+	// sendmailWaitDelay 进程被终止后等待输出管道关闭的时间（子进程可能仍持有管道）
+	sendmailWaitDelay = time.Second
+
+	// sendmailMaxStderr 附加到错误中的 stderr 最大字节数
+	sendmailMaxStderr = 4096
+)
+
+// SendmailConfig sendmail 驱动配置
+type SendmailConfig struct {
+	// Path sendmail 可执行文件路径（可选，默认 /usr/sbin/sendmail）
+	Path string `mapstructure:"path"`
+
+	// Args 命令参数（可选，默认 -t -i）；不包含 -t 时收件人作为参数传递
+	Args []string `mapstructure:"args"`
+
+	// Timeout 执行超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 邮件最大字节数（编码后），0 表示不限制
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// SendmailDriver 调用本地 sendmail 程序投递邮件（Postfix、Exim 等 MTA 均提供兼容命令）
+type SendmailDriver struct {
+	config *SendmailConfig
+}
+
+// NewSendmailDriver 创建 sendmail 驱动
+func NewSendmailDriver(config map[string]any) (Driver, error) {
+	cfg := &SendmailConfig{
+		Path:    SendmailDefaultPath,
+		Args:    []string{"-t", "-i"},
+		Timeout: 30 * time.Second,
+	}
+
+	// 解析配置
+	if path, ok := config["path"].(string); ok && path != "" {
+		cfg.Path = path
+	}
+	switch args := config["args"].(type) {
+	case []string:
+		cfg.Args = args
+	case []any:
+		cfg.Args = nil
+		for _, a := range args {
+			if arg, ok := a.(string); ok {
+				cfg.Args = append(cfg.Args, arg)
+			}
+		}
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	driver := &SendmailDriver{config: cfg}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// Name 驱动名称
+func (d *SendmailDriver) Name() string {
+	return DriverSendmail
+}
+
+// Validate 验证配置
+// 不检查可执行文件是否存在，便于在未安装 MTA 的环境中加载配置
+func (d *SendmailDriver) Validate() error {
+	if d.config.Path == "" {
+		return ErrDriverConfig.WithMsg("sendmail Path 不能为空")
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("sendmail MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+// 将组件构建的完整 MIME 邮件写入 sendmail 标准输入，退出码非 0 时返回包含 stderr 的错误；
+// VERP 模式下每个收件人调用一次
+func (d *SendmailDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireImmediate(); err != nil {
+		return nil, err
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+
+	body, err := newSMTPBody(msg)
+	if err != nil {
+		return nil, err
+	}
+	if max, size := d.config.MaxMessageSize, body.Size(); max > 0 && size > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", size, max)
+	}
+
+	if d.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.config.Timeout)
+		defer cancel()
+	}
+
+	// VERP 模式下每个收件人单独调用一次 sendmail
+	envelopes := envelopesOf(msg, recipientsOf(msg))
+	if len(envelopes) > 1 {
+		if err := body.requireRereadable(); err != nil {
+			return nil, err
+		}
+	}
+	var recipients []RecipientResult
+	for _, env := range envelopes {
+		if err := d.run(ctx, msg, env, body); err != nil {
+			if len(recipients) > 0 {
+				// 返回已投递的收件人，避免调用方重试时重复发送
+				return &Result{Status: "rejected", Recipients: recipients}, err
+			}
+			return nil, err
+		}
+		if msg.VERP {
+			recipients = append(recipients, RecipientResult{Email: env.recipients[0], Accepted: true})
+		}
+	}
+
+	return &Result{
+		MessageID:  fmt.Sprintf("sendmail-%d", time.Now().UnixNano()),
+		Status:     "queued",
+		Success:    true,
+		Recipients: recipients,
+	}, nil
+}
+
+// readsRecipients 参数包含 -t 时 sendmail 从邮件头读取收件人
+// VERP 模式下每次调用只投递给一个收件人，不使用 -t
+func (d *SendmailDriver) readsRecipients(msg *Message) bool {
+	return !msg.VERP && slices.Contains(d.config.Args, "-t")
+}
+
+// args 构建命令参数：配置参数、-f 信封发件人，不从邮件头读取收件人时追加收件人
+func (d *SendmailDriver) args(msg *Message, env smtpEnvelope) []string {
+	args := slices.Clone(d.config.Args)
+	if msg.VERP {
+		args = slices.DeleteFunc(args, func(arg string) bool { return arg == "-t" })
+	}
+	args = append(args, "-f", headerAddress(env.from))
+	if !d.readsRecipients(msg) {
+		args = append(args, "--")
+		args = append(args, headerAddresses(env.recipients)...)
+	}
+	return args
+}
+
+// run 执行 sendmail 并写入邮件内容
+func (d *SendmailDriver) run(ctx context.Context, msg *Message, env smtpEnvelope, body *smtpBody) error {
+	// 读取附件失败时需要终止进程，避免 sendmail 投递不完整的邮件
+	cmdCtx, kill := context.WithCancel(ctx)
+	defer kill()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(cmdCtx, d.config.Path, d.args(msg, env)...)
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = sendmailWaitDelay
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return ErrSendFailed.Wrap(err).WithMsg("创建 sendmail 输入管道失败")
+	}
+	if err := cmd.Start(); err != nil {
+		return ErrDriverConfig.Wrap(err).WithMsgf("启动 sendmail 失败: %s", d.config.Path)
+	}
+
+	writeErr := d.write(stdin, msg, body)
+	if isAppError(writeErr) {
+		kill()
+		_ = cmd.Wait()
+		return writeErr
+	}
+	stdin.Close()
+
+	// 进程提前退出时写入会失败，此时以退出状态为准
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ErrTimeout.Wrap(err).WithMsgf("sendmail 执行超时: %v", ctx.Err())
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			sendmailErr := &SendmailError{
+				ExitCode: exitErr.ExitCode(),
+				Stderr:   sendmailStderr(stderr.Bytes()),
+			}
+			return sendmailErrorCode(sendmailErr.ExitCode).Wrap(sendmailErr).WithMsgf("sendmail 投递失败: %s", sendmailErr.Error())
+		}
+		return ErrSendFailed.Wrap(err).WithMsg("sendmail 执行失败")
+	}
+	if writeErr != nil {
+		return ErrSendFailed.Wrap(writeErr).WithMsg("写入 sendmail 失败")
+	}
+	return nil
+}
+
+// write 写入邮件内容，使用 -t 时通过 Bcc 头传递密送收件人（sendmail 投递前移除该头）
+func (d *SendmailDriver) write(w io.Writer, msg *Message, body *smtpBody) error {
+	if d.readsRecipients(msg) && len(msg.Bcc) > 0 {
+		if _, err := fmt.Fprintf(w, "Bcc: %s\r\n", strings.Join(headerAddresses(msg.Bcc), ", ")); err != nil {
+			return err
+		}
+	}
+	_, err := body.WriteTo(w)
+	return err
+}
+
+// sendmailStderr 截取 stderr 输出
+func sendmailStderr(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	if len(s) > sendmailMaxStderr {
+		s = s[:sendmailMaxStderr] + "..."
+	}
+	return s
+}
+
+func init() {
+	// 注册 sendmail 驱动到默认注册表
+	RegisterDriver(DriverSendmail, NewSendmailDriver)
+}
@@ -0,0 +1,39 @@
+package email
+
+import (
+	"fmt"
+
+	"github.com/KOMKZ/go-yogan-framework/errcode"
+)
+
+// SendmailError sendmail 以非 0 状态退出时的错误，可通过 errors.As 获取
+type SendmailError struct {
+	// ExitCode 退出码（sysexits.h）
+	ExitCode int
+
+	// Stderr 标准错误输出（超长时截断）
+	Stderr string
+}
+
+// Error 实现 error 接口
+func (e *SendmailError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("exit status %d", e.ExitCode)
+	}
+	return fmt.Sprintf("exit status %d: %s", e.ExitCode, e.Stderr)
+}
+
+// sendmailErrorCode 将 sysexits.h 退出码映射为组件错误码
+func sendmailErrorCode(exitCode int) *errcode.AppError {
+	switch exitCode {
+	case 65: // EX_DATAERR
+		return ErrInvalidMessage
+	case 67, 68: // EX_NOUSER, EX_NOHOST
+		return ErrInvalidRecipient
+	case 64, 73, 77, 78: // EX_USAGE, EX_CANTCREAT, EX_NOPERM, EX_CONFIG
+		return ErrDriverConfig
+	default:
+		// EX_UNAVAILABLE、EX_TEMPFAIL 等
+		return ErrSendFailed
+	}
+}
@@ -0,0 +1,337 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// fakeSendmail 在临时目录中生成模拟 sendmail 的脚本，返回脚本路径与输出目录
+func fakeSendmail(t *testing.T, script string) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("sendmail driver tests require /bin/sh")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sendmail")
+	content := "#!/bin/sh\nOUT=" + dir + "\n" + script
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path, dir
+}
+
+// readFakeOutput 读取脚本输出文件
+func readFakeOutput(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+const fakeSendmailRecorder = `printf '%s\n' "$@" > "$OUT/args"
+cat > "$OUT/stdin.tmp" && mv "$OUT/stdin.tmp" "$OUT/stdin"
+`
+
+func TestNewSendmailDriver(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]any
+		wantPath string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			config:   map[string]any{},
+			wantPath: SendmailDefaultPath,
+			wantArgs: []string{"-t", "-i"},
+		},
+		{
+			name:     "custom args",
+			config:   map[string]any{"path": "/usr/bin/msmtp", "args": []any{"-i", "-C", "/etc/msmtprc"}},
+			wantPath: "/usr/bin/msmtp",
+			wantArgs: []string{"-i", "-C", "/etc/msmtprc"},
+		},
+		{
+			name:    "invalid max size",
+			config:  map[string]any{"max_message_size": -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := NewSendmailDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSendmailDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			cfg := driver.(*SendmailDriver).config
+			if cfg.Path != tt.wantPath || strings.Join(cfg.Args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("unexpected config: %s %v", cfg.Path, cfg.Args)
+			}
+		})
+	}
+}
+
+func TestSendmailDriver_Send(t *testing.T) {
+	path, dir := fakeSendmail(t, fakeSendmailRecorder)
+	driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:        "noreply@example.com",
+		FromName:    "Example",
+		To:          []string{"a@example.com"},
+		Bcc:         []string{"bcc@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "report.pdf", Content: []byte("%PDF-1.4")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success || result.Status != "queued" || !strings.HasPrefix(result.MessageID, "sendmail-") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if args := readFakeOutput(t, dir, "args"); args != "-t\n-i\n-f\nnoreply@example.com\n" {
+		t.Errorf("unexpected args: %q", args)
+	}
+	stdin := readFakeOutput(t, dir, "stdin")
+	if !strings.HasPrefix(stdin, "Bcc: bcc@example.com\r\n") {
+		t.Errorf("expected Bcc header first, got:\n%s", stdin)
+	}
+	for _, want := range []string{"To: a@example.com\r\n", "Subject: Test\r\n", `filename="report.pdf"`} {
+		if !strings.Contains(stdin, want) {
+			t.Errorf("message missing %q", want)
+		}
+	}
+}
+
+func TestSendmailDriver_Send_RecipientArgs(t *testing.T) {
+	path, dir := fakeSendmail(t, fakeSendmailRecorder)
+	driver, _ := NewSendmailDriver(map[string]any{"path": path, "args": []string{"-i"}})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "noreply@example.com",
+		To:       []string{"a@example.com"},
+		Cc:       []string{"cc@example.com"},
+		Bcc:      []string{"bcc@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "-i\n-f\nnoreply@example.com\n--\na@example.com\ncc@example.com\nbcc@example.com\n"
+	if args := readFakeOutput(t, dir, "args"); args != want {
+		t.Errorf("unexpected args: %q", args)
+	}
+	if stdin := readFakeOutput(t, dir, "stdin"); strings.Contains(stdin, "Bcc:") {
+		t.Error("Bcc header must not be written without -t")
+	}
+}
+
+func TestSendmailDriver_Send_EnvelopeFrom(t *testing.T) {
+	path, dir := fakeSendmail(t, fakeSendmailRecorder)
+	driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:         "noreply@example.com",
+		EnvelopeFrom: "bounces@example.com",
+		To:           []string{"a@example.com"},
+		Subject:      "Test",
+		BodyText:     "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args := readFakeOutput(t, dir, "args"); args != "-t\n-i\n-f\nbounces@example.com\n" {
+		t.Errorf("unexpected args: %q", args)
+	}
+}
+
+func TestSendmailDriver_Send_VERP(t *testing.T) {
+	// 每次调用追加一组参数
+	path, dir := fakeSendmail(t, `printf '%s\n' "$@" >> "$OUT/args"
+echo "---" >> "$OUT/args"
+cat > /dev/null
+`)
+	driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:         "noreply@example.com",
+		EnvelopeFrom: "bounces@example.com",
+		VERP:         true,
+		To:           []string{"a@example.com"},
+		Bcc:          []string{"b@example.org"},
+		Subject:      "Test",
+		BodyText:     "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 不使用 -t，每个收件人单独投递，信封发件人编码收件人地址
+	want := "-i\n-f\nbounces+a=example.com@example.com\n--\na@example.com\n---\n" +
+		"-i\n-f\nbounces+b=example.org@example.com\n--\nb@example.org\n---\n"
+	if args := readFakeOutput(t, dir, "args"); args != want {
+		t.Errorf("unexpected args: %q", args)
+	}
+	if len(result.Recipients) != 2 || !result.Recipients[0].Accepted || result.Recipients[1].Email != "b@example.org" {
+		t.Errorf("unexpected recipients: %+v", result.Recipients)
+	}
+}
+
+func TestSendmailDriver_Send_VERPNonSeekableReader(t *testing.T) {
+	path, dir := fakeSendmail(t, `echo called >> "$OUT/calls"
+cat > /dev/null
+`)
+	driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+	// 只能读取一次的 Reader 无法写入多次，需要在第一次调用 sendmail 之前拒绝
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "bounces@example.com",
+		VERP:     true,
+		To:       []string{"a@example.com", "b@example.org"},
+		Subject:  "Test",
+		BodyText: "Hello",
+		Attachments: []Attachment{{
+			Filename:    "r.txt",
+			ContentType: "text/plain",
+			Reader:      io.MultiReader(strings.NewReader("data")),
+		}},
+	})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "calls")); !os.IsNotExist(err) {
+		t.Error("expected sendmail not to be called")
+	}
+}
+
+func TestSendmailDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		wantErr  error
+	}{
+		{"no user", 67, ErrInvalidRecipient},
+		{"data error", 65, ErrInvalidMessage},
+		{"config error", 78, ErrDriverConfig},
+		{"temp fail", 75, ErrSendFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _ := fakeSendmail(t, fmt.Sprintf("cat > /dev/null\necho 'sendmail: fatal: recipient rejected' >&2\nexit %d\n", tt.exitCode))
+			driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+			_, err := driver.Send(context.Background(), &Message{
+				From: "noreply@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var sendmailErr *SendmailError
+			if !errors.As(err, &sendmailErr) {
+				t.Fatalf("expected SendmailError, got %v", err)
+			}
+			if sendmailErr.ExitCode != tt.exitCode || sendmailErr.Stderr != "sendmail: fatal: recipient rejected" {
+				t.Errorf("unexpected error: %+v", sendmailErr)
+			}
+			if !strings.Contains(err.Error(), "recipient rejected") {
+				t.Errorf("expected stderr in error message, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSendmailDriver_Send_ExitWithoutReading(t *testing.T) {
+	path, _ := fakeSendmail(t, "echo 'bad option' >&2\nexit 64\n")
+	driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:        "noreply@example.com",
+		To:          []string{"a@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "data.bin", Content: make([]byte, 1<<20)}},
+	})
+	var sendmailErr *SendmailError
+	if !errors.Is(err, ErrDriverConfig) || !errors.As(err, &sendmailErr) || sendmailErr.Stderr != "bad option" {
+		t.Errorf("expected exit status error, got %v", err)
+	}
+}
+
+func TestSendmailDriver_Send_ContextCanceled(t *testing.T) {
+	path, _ := fakeSendmail(t, "exec sleep 10\n")
+	driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := driver.Send(ctx, &Message{
+		From: "noreply@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected cancellation to stop sendmail, took %v", elapsed)
+	}
+}
+
+func TestSendmailDriver_Send_AttachmentReadError(t *testing.T) {
+	path, dir := fakeSendmail(t, fakeSendmailRecorder)
+	driver, _ := NewSendmailDriver(map[string]any{"path": path})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "noreply@example.com",
+		To:       []string{"a@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+		Attachments: []Attachment{
+			{Filename: "broken.bin", Reader: iotest.ErrReader(errors.New("disk error")), ContentType: "application/octet-stream"},
+		},
+	})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+	// 进程被终止，不完整的邮件不会被投递
+	if _, err := os.Stat(filepath.Join(dir, "stdin")); !os.IsNotExist(err) {
+		t.Error("expected truncated message not to be delivered")
+	}
+}
+
+func TestSendmailDriver_Send_NotFound(t *testing.T) {
+	driver, _ := NewSendmailDriver(map[string]any{"path": filepath.Join(t.TempDir(), "missing")})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From: "noreply@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrDriverConfig) {
+		t.Errorf("expected ErrDriverConfig, got %v", err)
+	}
+}
+
+func TestDefaultRegistry_Sendmail(t *testing.T) {
+	if !DefaultRegistry.Has(DriverSendmail) {
+		t.Error("expected sendmail driver to be registered")
+	}
+}
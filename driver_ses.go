@@ -0,0 +1,340 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverSES AWS SES (v2 API) 驱动名称
+	DriverSES = "ses"
+
+	// SESDefaultMaxMessageSize SES 单封邮件大小上限（40MB）
+	SESDefaultMaxMessageSize = 40 << 20
+
+	// sesSendEmailPath SESv2 SendEmail 接口路径
+	sesSendEmailPath = "/v2/email/outbound-emails"
+)
+
+// SESConfig SES 驱动配置
+type SESConfig struct {
+	// Region AWS 区域（如 us-east-1）
+	Region string `mapstructure:"region"`
+
+	// AccessKeyID 访问密钥 ID
+	AccessKeyID string `mapstructure:"access_key_id"`
+
+	// SecretAccessKey 访问密钥
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+
+	// SessionToken 临时凭据的会话令牌（可选）
+	SessionToken string `mapstructure:"session_token"`
+
+	// Endpoint API 地址（可选，默认 https://email.{region}.amazonaws.com）
+	Endpoint string `mapstructure:"endpoint"`
+
+	// ConfigurationSet 配置集名称（可选，用于事件发布与 IP 池）
+	ConfigurationSet string `mapstructure:"configuration_set"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 邮件最大字节数（可选，默认 40MB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// SESDriver AWS SES 邮件驱动（SESv2 SendEmail）
+type SESDriver struct {
+	config *SESConfig
+	client *http.Client
+	signer *sigV4Signer
+}
+
+// NewSESDriver 创建 SES 驱动
+func NewSESDriver(config map[string]any) (Driver, error) {
+	cfg := &SESConfig{
+		Timeout:        30 * time.Second,
+		MaxMessageSize: SESDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if region, ok := config["region"].(string); ok {
+		cfg.Region = region
+	}
+	if accessKeyID, ok := config["access_key_id"].(string); ok {
+		cfg.AccessKeyID = accessKeyID
+	}
+	if secret, ok := config["secret_access_key"].(string); ok {
+		cfg.SecretAccessKey = secret
+	}
+	if token, ok := config["session_token"].(string); ok {
+		cfg.SessionToken = token
+	}
+	if endpoint, ok := config["endpoint"].(string); ok && endpoint != "" {
+		cfg.Endpoint = strings.TrimRight(endpoint, "/")
+	}
+	if configurationSet, ok := config["configuration_set"].(string); ok {
+		cfg.ConfigurationSet = configurationSet
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	if cfg.Endpoint == "" && cfg.Region != "" {
+		cfg.Endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", cfg.Region)
+	}
+
+	driver := &SESDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		signer: &sigV4Signer{
+			accessKeyID:     cfg.AccessKeyID,
+			secretAccessKey: cfg.SecretAccessKey,
+			sessionToken:    cfg.SessionToken,
+			region:          cfg.Region,
+			service:         "ses",
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// Name 驱动名称
+func (d *SESDriver) Name() string {
+	return DriverSES
+}
+
+// Validate 验证配置
+func (d *SESDriver) Validate() error {
+	if d.config.Region == "" {
+		return ErrDriverConfig.WithMsg("SES Region 不能为空")
+	}
+	if d.config.AccessKeyID == "" || d.config.SecretAccessKey == "" {
+		return ErrDriverConfig.WithMsg("SES AccessKeyID 与 SecretAccessKey 不能为空")
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("SES MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+func (d *SESDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireBody(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireImmediate(); err != nil {
+		return nil, err
+	}
+
+	// 推断附件类型
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+
+	// 构建请求体
+	payload, err := d.buildPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.doRequest(ctx, payload)
+}
+
+// sesContent SESv2 邮件内容字段
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset,omitempty"`
+}
+
+// buildPayload 构建 SendEmail 请求体
+// 无附件时使用 Simple 内容，有附件时使用完整 MIME 的 Raw 内容
+func (d *SESDriver) buildPayload(msg *Message) (map[string]any, error) {
+	from := headerAddress(msg.From)
+	if msg.FromName != "" {
+		from = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("UTF-8", msg.FromName), headerAddress(msg.From))
+	}
+
+	destination := map[string]any{
+		"ToAddresses": headerAddresses(msg.To),
+	}
+	if len(msg.Cc) > 0 {
+		destination["CcAddresses"] = headerAddresses(msg.Cc)
+	}
+	if len(msg.Bcc) > 0 {
+		destination["BccAddresses"] = headerAddresses(msg.Bcc)
+	}
+
+	payload := map[string]any{
+		"FromEmailAddress": from,
+		"Destination":      destination,
+	}
+	if msg.ReplyTo != "" {
+		payload["ReplyToAddresses"] = []string{headerAddress(msg.ReplyTo)}
+	}
+	// 信封发件人: SES 不支持自定义 Return-Path，退信与投诉转发到该地址
+	if msg.EnvelopeFrom != "" {
+		payload["FeedbackForwardingEmailAddress"] = msg.EnvelopeFrom
+	}
+	if d.config.ConfigurationSet != "" {
+		payload["ConfigurationSetName"] = d.config.ConfigurationSet
+	}
+
+	// 消息标签（按名称排序，保证请求稳定）
+	if len(msg.Tags) > 0 {
+		names := make([]string, 0, len(msg.Tags))
+		for name := range msg.Tags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		tags := make([]map[string]string, 0, len(names))
+		for _, name := range names {
+			tags = append(tags, map[string]string{"Name": name, "Value": msg.Tags[name]})
+		}
+		payload["EmailTags"] = tags
+	}
+
+	if len(msg.Attachments) > 0 {
+		raw, err := d.buildRaw(msg)
+		if err != nil {
+			return nil, err
+		}
+		payload["Content"] = map[string]any{
+			"Raw": map[string]any{"Data": raw},
+		}
+		return payload, nil
+	}
+
+	body := map[string]any{}
+	if msg.BodyText != "" {
+		body["Text"] = sesContent{Data: msg.BodyText, Charset: "UTF-8"}
+	}
+	if msg.BodyHTML != "" {
+		body["Html"] = sesContent{Data: msg.BodyHTML, Charset: "UTF-8"}
+	}
+	simple := map[string]any{
+		"Subject": sesContent{Data: msg.Subject, Charset: "UTF-8"},
+		"Body":    body,
+	}
+
+	// 自定义头
+	if len(msg.Headers) > 0 {
+		names := make([]string, 0, len(msg.Headers))
+		for name := range msg.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		headers := make([]map[string]string, 0, len(names))
+		for _, name := range names {
+			headers = append(headers, map[string]string{"Name": name, "Value": msg.Headers[name]})
+		}
+		simple["Headers"] = headers
+	}
+
+	payload["Content"] = map[string]any{"Simple": simple}
+	return payload, nil
+}
+
+// buildRaw 构建原始 MIME 邮件（JSON 序列化时自动 base64 编码）
+func (d *SESDriver) buildRaw(msg *Message) ([]byte, error) {
+	body, err := newSMTPBody(msg)
+	if err != nil {
+		return nil, err
+	}
+	if max, size := d.config.MaxMessageSize, body.Size(); max > 0 && size > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", size, max)
+	}
+
+	var buf bytes.Buffer
+	if size := body.Size(); size > 0 {
+		buf.Grow(int(size))
+	}
+	if _, err := body.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// doRequest 发送签名后的 SendEmail 请求
+func (d *SESDriver) doRequest(ctx context.Context, payload map[string]any) (*Result, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("序列化请求失败")
+	}
+
+	// 发送前检查大小限制
+	if max := d.config.MaxMessageSize; max > 0 && int64(len(body)) > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", len(body), max)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.config.Endpoint+sesSendEmailPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	d.signer.sign(req, body, time.Now())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	// 解析响应
+	return d.parseResponse(resp, respBody)
+}
+
+// parseResponse 解析 SendEmail 响应
+func (d *SESDriver) parseResponse(resp *http.Response, body []byte) (*Result, error) {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var out struct {
+			MessageID string `json:"MessageId"`
+		}
+		if err := json.Unmarshal(body, &out); err != nil {
+			return nil, ErrSendFailed.Wrap(err).WithMsg("解析响应失败")
+		}
+		return &Result{
+			MessageID: out.MessageID,
+			Status:    "sent",
+			Success:   true,
+		}, nil
+	}
+
+	sesErr := newSESError(resp, body)
+	return nil, sesErrorCode(sesErr).Wrap(sesErr).WithMsgf("SES API 错误: %s - %s", sesErr.Code, sesErr.Message)
+}
+
+func init() {
+	// 注册 SES 驱动到默认注册表
+	RegisterDriver(DriverSES, NewSESDriver)
+}
@@ -0,0 +1,100 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KOMKZ/go-yogan-framework/errcode"
+)
+
+// SESError SES API 返回的错误，可通过 errors.As 获取
+type SESError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+
+	// Code 错误类型（如 MessageRejected、TooManyRequestsException）
+	Code string
+
+	// Message 错误描述
+	Message string
+
+	// RequestID 请求 ID（x-amzn-RequestId），用于向 AWS 排查问题
+	RequestID string
+}
+
+// Error 实现 error 接口
+func (e *SESError) Error() string {
+	return fmt.Sprintf("ses %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// newSESError 从错误响应解析 SESError
+// 错误类型优先取 X-Amzn-ErrorType 头（形如 "MessageRejected:http://..."），其次取响应体 __type/code
+func newSESError(resp *http.Response, body []byte) *SESError {
+	e := &SESError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Amzn-Requestid"),
+	}
+
+	var out struct {
+		Type         string `json:"__type"`
+		Code         string `json:"code"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	_ = json.Unmarshal(body, &out)
+
+	code := resp.Header.Get("X-Amzn-Errortype")
+	if code == "" {
+		code = out.Type
+	}
+	if code == "" {
+		code = out.Code
+	}
+	if i := strings.Index(code, ":"); i >= 0 {
+		code = code[:i]
+	}
+	if i := strings.LastIndex(code, "#"); i >= 0 {
+		code = code[i+1:]
+	}
+	e.Code = code
+	if e.Code == "" {
+		e.Code = http.StatusText(resp.StatusCode)
+	}
+
+	e.Message = out.Message
+	if e.Message == "" {
+		e.Message = out.MessageUpper
+	}
+	if e.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// sesErrorCode 将 SES 错误类型映射为组件错误码
+func sesErrorCode(e *SESError) *errcode.AppError {
+	switch e.Code {
+	case "TooManyRequestsException", "Throttling", "ThrottlingException", "LimitExceededException":
+		return ErrRateLimited
+	case "MailFromDomainNotVerifiedException", "MailFromDomainNotVerified", "NotFoundException":
+		return ErrDriverConfig
+	case "UnrecognizedClientException", "InvalidSignatureException", "SignatureDoesNotMatch",
+		"AccessDeniedException", "ExpiredTokenException", "IncompleteSignature":
+		return ErrAuthFailed
+	case "BadRequestException", "ValidationException":
+		return ErrInvalidMessage
+	case "MessageRejected", "AccountSuspendedException", "SendingPausedException":
+		return ErrSendFailed
+	}
+
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuthFailed
+	default:
+		return ErrSendFailed
+	}
+}
@@ -0,0 +1,288 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewSESDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			config: map[string]any{
+				"region":            "us-east-1",
+				"access_key_id":     "AKID",
+				"secret_access_key": "secret",
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing region",
+			config: map[string]any{
+				"access_key_id":     "AKID",
+				"secret_access_key": "secret",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing credentials",
+			config: map[string]any{
+				"region": "us-east-1",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSESDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSESDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSESDriver_DefaultEndpoint(t *testing.T) {
+	driver, err := NewSESDriver(map[string]any{
+		"region":            "eu-west-1",
+		"access_key_id":     "AKID",
+		"secret_access_key": "secret",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	if got := driver.(*SESDriver).config.Endpoint; got != "https://email.eu-west-1.amazonaws.com" {
+		t.Errorf("unexpected endpoint: %s", got)
+	}
+	if driver.Name() != DriverSES {
+		t.Errorf("expected name %s, got %s", DriverSES, driver.Name())
+	}
+}
+
+// newTestSESDriver 创建指向本地服务器的 SES 驱动
+func newTestSESDriver(t *testing.T, handler http.HandlerFunc) Driver {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	driver, err := NewSESDriver(map[string]any{
+		"region":            "us-east-1",
+		"access_key_id":     "AKIDEXAMPLE",
+		"secret_access_key": "secret",
+		"session_token":     "session",
+		"endpoint":          server.URL,
+		"configuration_set": "transactional",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver
+}
+
+func TestSESDriver_Send_Simple(t *testing.T) {
+	var payload map[string]any
+	var header http.Header
+	var path string
+	driver := newTestSESDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		header = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode(map[string]string{"MessageId": "ses-123"})
+	})
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		FromName: "Sender",
+		To:       []string{"to@example.com"},
+		Cc:       []string{"cc@example.com"},
+		Bcc:      []string{"bcc@example.com"},
+		ReplyTo:  "reply@example.com",
+		Subject:  "Test",
+		BodyHTML: "<h1>Hello</h1>",
+		BodyText: "Hello",
+		Headers:  map[string]string{"X-Campaign": "welcome"},
+		Tags:     map[string]string{"type": "welcome", "env": "test"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success || result.MessageID != "ses-123" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if path != "/v2/email/outbound-emails" {
+		t.Errorf("unexpected path: %s", path)
+	}
+	auth := header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/us-east-1/ses/aws4_request") {
+		t.Errorf("unexpected Authorization header: %s", auth)
+	}
+	if header.Get("X-Amz-Security-Token") != "session" || header.Get("X-Amz-Date") == "" {
+		t.Errorf("expected signing headers, got %v", header)
+	}
+
+	if payload["FromEmailAddress"] != "Sender <sender@example.com>" {
+		t.Errorf("unexpected from: %v", payload["FromEmailAddress"])
+	}
+	if payload["ConfigurationSetName"] != "transactional" {
+		t.Errorf("unexpected configuration set: %v", payload["ConfigurationSetName"])
+	}
+	destination := payload["Destination"].(map[string]any)
+	if len(destination["BccAddresses"].([]any)) != 1 || len(destination["CcAddresses"].([]any)) != 1 {
+		t.Errorf("unexpected destination: %v", destination)
+	}
+	tags := payload["EmailTags"].([]any)
+	if len(tags) != 2 || tags[0].(map[string]any)["Name"] != "env" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	simple := payload["Content"].(map[string]any)["Simple"].(map[string]any)
+	if simple["Subject"].(map[string]any)["Data"] != "Test" {
+		t.Errorf("unexpected subject: %v", simple["Subject"])
+	}
+	body := simple["Body"].(map[string]any)
+	if body["Html"].(map[string]any)["Data"] != "<h1>Hello</h1>" || body["Text"].(map[string]any)["Data"] != "Hello" {
+		t.Errorf("unexpected body: %v", body)
+	}
+	if len(simple["Headers"].([]any)) != 1 {
+		t.Errorf("unexpected headers: %v", simple["Headers"])
+	}
+}
+
+func TestSESDriver_Send_RawWithAttachments(t *testing.T) {
+	var payload map[string]any
+	driver := newTestSESDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode(map[string]string{"MessageId": "ses-raw"})
+	})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Bcc:      []string{"bcc@example.com"},
+		Subject:  "Report",
+		BodyText: "See attached",
+		Attachments: []Attachment{
+			{Filename: "report.pdf", Content: []byte("%PDF-1.4")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw := payload["Content"].(map[string]any)["Raw"].(map[string]any)
+	data, err := base64.StdEncoding.DecodeString(raw["Data"].(string))
+	if err != nil {
+		t.Fatalf("invalid raw data: %v", err)
+	}
+	mime := string(data)
+	for _, want := range []string{"Subject: Report", "multipart/mixed", `Content-Type: application/pdf; name="report.pdf"`} {
+		if !strings.Contains(mime, want) {
+			t.Errorf("expected raw message to contain %q, got:\n%s", want, mime)
+		}
+	}
+	if strings.Contains(mime, "bcc@example.com") {
+		t.Error("expected Bcc to be omitted from raw headers")
+	}
+	destination := payload["Destination"].(map[string]any)
+	if len(destination["BccAddresses"].([]any)) != 1 {
+		t.Errorf("expected Bcc in destination, got %v", destination)
+	}
+}
+
+func TestSESDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		errorType string
+		body      string
+		wantErr   error
+		wantCode  string
+	}{
+		{
+			name:      "throttling",
+			status:    http.StatusTooManyRequests,
+			errorType: "TooManyRequestsException:http://internal.amazon.com/coral/com.amazon.coral.service/",
+			body:      `{"message":"Maximum sending rate exceeded."}`,
+			wantErr:   ErrRateLimited,
+			wantCode:  "TooManyRequestsException",
+		},
+		{
+			name:     "throttling in body",
+			status:   http.StatusBadRequest,
+			body:     `{"__type":"com.amazon.coral.availability#Throttling","message":"Rate exceeded"}`,
+			wantErr:  ErrRateLimited,
+			wantCode: "Throttling",
+		},
+		{
+			name:      "message rejected",
+			status:    http.StatusBadRequest,
+			errorType: "MessageRejected",
+			body:      `{"message":"Email address is not verified."}`,
+			wantErr:   ErrSendFailed,
+			wantCode:  "MessageRejected",
+		},
+		{
+			name:      "mail from domain not verified",
+			status:    http.StatusBadRequest,
+			errorType: "MailFromDomainNotVerifiedException",
+			body:      `{"message":"MAIL FROM domain is not verified"}`,
+			wantErr:   ErrDriverConfig,
+			wantCode:  "MailFromDomainNotVerifiedException",
+		},
+		{
+			name:     "invalid signature",
+			status:   http.StatusForbidden,
+			body:     `{"Message":"The request signature we calculated does not match"}`,
+			wantErr:  ErrAuthFailed,
+			wantCode: "Forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := newTestSESDriver(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.errorType != "" {
+					w.Header().Set("X-Amzn-ErrorType", tt.errorType)
+				}
+				w.Header().Set("X-Amzn-RequestId", "req-1")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := driver.Send(context.Background(), &Message{
+				From:     "sender@example.com",
+				To:       []string{"to@example.com"},
+				Subject:  "Test",
+				BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var sesErr *SESError
+			if !errors.As(err, &sesErr) {
+				t.Fatalf("expected SESError, got %v", err)
+			}
+			if sesErr.Code != tt.wantCode || sesErr.StatusCode != tt.status || sesErr.RequestID != "req-1" {
+				t.Errorf("unexpected SESError: %+v", sesErr)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistry_SES(t *testing.T) {
+	if !DefaultRegistry.Has(DriverSES) {
+		t.Error("expected ses driver to be registered")
+	}
+}
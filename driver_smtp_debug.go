@@ -0,0 +1,253 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+
+	"github.com/KOMKZ/go-yogan-framework/logger"
+	"go.uber.org/zap"
+)
+
+// SetDebugWriter 设置 SMTP 协议记录的输出（debug 开启时生效）
+func (d *SMTPDriver) SetDebugWriter(w io.Writer) {
+	d.debugWriter = w
+}
+
+// SetDebugLogger 设置 SMTP 协议记录的日志器（debug 开启时以 Debug 级别输出）
+func (d *SMTPDriver) SetDebugLogger(log *logger.CtxZapLogger) {
+	d.debugLogger = log
+}
+
+// newTranscript 创建会话记录，未开启 debug 时返回 nil
+func (d *SMTPDriver) newTranscript(host string) *smtpTranscript {
+	if !d.config.Debug {
+		return nil
+	}
+	return &smtpTranscript{
+		host:   host,
+		max:    d.config.DebugLines,
+		writer: d.debugWriter,
+		logger: d.debugLogger,
+	}
+}
+
+// smtpTranscript SMTP 会话记录
+// 记录客户端（C:）与服务器（S:）的交互，AUTH 凭据与邮件正文会被脱敏
+type smtpTranscript struct {
+	host   string
+	max    int
+	writer io.Writer
+	logger *logger.CtxZapLogger
+
+	mu        sync.Mutex
+	lines     []string
+	clientBuf []byte
+	serverBuf []byte
+
+	authPending bool
+	dataPending bool
+	inBody      bool
+	bodyBytes   int
+}
+
+// Lines 获取最近的记录行
+func (t *smtpTranscript) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.lines...)
+}
+
+// attach 在 SMTP 客户端的文本连接上挂载记录（STARTTLS 后需重新挂载）
+func (t *smtpTranscript) attach(client *smtp.Client) {
+	client.Text.Reader.R = bufio.NewReader(&transcriptReader{r: client.Text.Reader.R, t: t})
+	client.Text.Writer.W = bufio.NewWriter(&transcriptWriter{w: client.Text.Writer.W, t: t})
+}
+
+// clientData 处理客户端发送的数据
+func (t *smtpTranscript) clientData(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clientBuf = append(t.clientBuf, p...)
+	for {
+		i := bytes.Index(t.clientBuf, []byte("\r\n"))
+		if i < 0 {
+			return
+		}
+		line := string(t.clientBuf[:i])
+		t.clientBuf = t.clientBuf[i+2:]
+		t.clientLine(line)
+	}
+}
+
+// clientLine 记录一行客户端数据（需持有锁）
+func (t *smtpTranscript) clientLine(line string) {
+	switch {
+	case t.inBody:
+		if line == "." {
+			t.record(fmt.Sprintf("C: <message body redacted, %d bytes>", t.bodyBytes))
+			t.record("C: .")
+			t.inBody = false
+			return
+		}
+		t.bodyBytes += len(line) + 2
+	case t.authPending:
+		t.record("C: <redacted>")
+	case strings.HasPrefix(strings.ToUpper(line), "AUTH "):
+		// 保留认证机制，隐藏初始响应
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			line = fields[0] + " " + fields[1] + " <redacted>"
+		}
+		t.authPending = true
+		t.record("C: " + line)
+	default:
+		if strings.ToUpper(line) == "DATA" {
+			t.dataPending = true
+		}
+		t.record("C: " + line)
+	}
+}
+
+// serverData 处理服务器返回的数据
+func (t *smtpTranscript) serverData(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.serverBuf = append(t.serverBuf, p...)
+	for {
+		i := bytes.IndexByte(t.serverBuf, '\n')
+		if i < 0 {
+			return
+		}
+		line := strings.TrimRight(string(t.serverBuf[:i]), "\r")
+		t.serverBuf = t.serverBuf[i+1:]
+
+		code := line
+		if len(code) > 3 {
+			code = code[:3]
+		}
+		if t.authPending && code != "334" {
+			t.authPending = false
+		}
+		if t.dataPending {
+			t.dataPending = false
+			if code == "354" {
+				t.inBody = true
+				t.bodyBytes = 0
+			}
+		}
+		t.record("S: " + line)
+	}
+}
+
+// record 追加一行记录并输出（需持有锁）
+func (t *smtpTranscript) record(line string) {
+	t.lines = append(t.lines, line)
+	if t.max > 0 && len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+
+	if t.writer != nil {
+		fmt.Fprintf(t.writer, "[smtp %s] %s\n", t.host, line)
+	}
+	if t.logger != nil {
+		t.logger.Debug("smtp transcript", zap.String("host", t.host), zap.String("line", line))
+	}
+}
+
+// transcriptReader 记录服务器数据的读取器
+type transcriptReader struct {
+	r io.Reader
+	t *smtpTranscript
+}
+
+// Read 实现 io.Reader
+func (r *transcriptReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.t.serverData(p[:n])
+	}
+	return n, err
+}
+
+// transcriptWriter 记录客户端数据的写入器
+type transcriptWriter struct {
+	w *bufio.Writer
+	t *smtpTranscript
+}
+
+// Write 实现 io.Writer
+func (w *transcriptWriter) Write(p []byte) (int, error) {
+	w.t.clientData(p)
+	n, err := w.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.w.Flush()
+}
+
+// transcriptConn 在创建 SMTP 客户端前记录欢迎消息的连接
+// 客户端创建后切换为文本连接层记录（避免 STARTTLS 后记录到密文）
+type transcriptConn struct {
+	net.Conn
+	t *smtpTranscript
+
+	mu      sync.Mutex
+	enabled bool
+}
+
+// Read 实现 io.Reader
+func (c *transcriptConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.mu.Lock()
+	enabled := c.enabled
+	c.mu.Unlock()
+	if n > 0 && enabled {
+		c.t.serverData(p[:n])
+	}
+	return n, err
+}
+
+// disable 停止连接层记录
+func (c *transcriptConn) disable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = false
+}
+
+// transcriptError 附带 SMTP 会话记录的错误（err 为空时仅包含会话记录）
+type transcriptError struct {
+	err   error
+	lines []string
+}
+
+// Error 实现 error 接口
+func (e *transcriptError) Error() string {
+	transcript := "SMTP transcript:\n" + strings.Join(e.lines, "\n")
+	if e.err == nil {
+		return transcript
+	}
+	return e.err.Error() + "\n" + transcript
+}
+
+// Unwrap 返回原始错误
+func (e *transcriptError) Unwrap() error {
+	return e.err
+}
+
+// SMTPTranscript 从错误中提取 SMTP 会话记录（debug 开启时附加的最近 N 行）
+func SMTPTranscript(err error) []string {
+	var te *transcriptError
+	if errors.As(err, &te) {
+		return te.lines
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSMTPDriver_Send_DebugTranscript(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		upper := strings.ToUpper(cmd)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"):
+			return "250-localhost\r\n250 AUTH PLAIN"
+		case strings.HasPrefix(upper, "AUTH"):
+			return "235 2.7.0 Authentication successful"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":     srv.host,
+		"port":     srv.port,
+		"username": "user",
+		"password": "secret",
+		"debug":    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	var buf bytes.Buffer
+	driver.(*SMTPDriver).SetDebugWriter(&buf)
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Top secret subject",
+		BodyText: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	transcript := buf.String()
+	for _, want := range []string{
+		"S: 220 Mock SMTP Server",
+		"C: EHLO localhost",
+		"C: AUTH PLAIN <redacted>",
+		"C: MAIL FROM:<sender@example.com>",
+		"C: <message body redacted,",
+		"S: 250 OK queued",
+		"C: QUIT",
+	} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("expected transcript to contain %q, got:\n%s", want, transcript)
+		}
+	}
+	if strings.Contains(transcript, "Top secret subject") {
+		t.Errorf("expected message body to be redacted, got:\n%s", transcript)
+	}
+	if strings.Contains(transcript, "AHVzZXIAc2VjcmV0") {
+		t.Errorf("expected AUTH payload to be redacted, got:\n%s", transcript)
+	}
+}
+
+func TestSMTPDriver_Send_DebugTranscriptOnError(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "RCPT TO") {
+			return "550 5.1.1 Mailbox unavailable"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":        srv.host,
+		"port":        srv.port,
+		"debug":       true,
+		"debug_lines": 2,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	lines := SMTPTranscript(err)
+	want := []string{
+		"C: RCPT TO:<to@example.com>",
+		"S: 550 5.1.1 Mailbox unavailable",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected transcript %v, got %v", want, lines)
+	}
+
+	var smtpErr *SMTPError
+	if !errors.As(err, &smtpErr) || smtpErr.Code != 550 {
+		t.Errorf("expected SMTPError to remain in chain, got %v", err)
+	}
+}
+
+func TestSMTPDriver_Send_NoTranscriptWithoutDebug(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "RCPT TO") {
+			return "550 5.1.1 Mailbox unavailable"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if lines := SMTPTranscript(err); lines != nil {
+		t.Errorf("expected no transcript, got %v", lines)
+	}
+}
+
+func TestSMTPDriver_Send_DebugTranscriptOnSizeLimit(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "EHLO") {
+			return "250-localhost\r\n250 SIZE 10"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":  srv.host,
+		"port":  srv.port,
+		"debug": true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+
+	// 会话记录需要包含服务器声明的 SIZE 上限
+	transcript := strings.Join(SMTPTranscript(err), "\n")
+	if !strings.Contains(transcript, "S: 250 SIZE 10") {
+		t.Errorf("expected transcript to contain SIZE reply, got:\n%s", transcript)
+	}
+}
+
+func TestSMTPDriver_Send_DebugTranscriptAllRejected(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "RCPT TO") {
+			return "550 5.1.1 Mailbox unavailable"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":             srv.host,
+		"port":             srv.port,
+		"partial_delivery": true,
+		"debug":            true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Fatalf("expected ErrInvalidRecipient, got %v", err)
+	}
+
+	transcript := strings.Join(SMTPTranscript(err), "\n")
+	for _, want := range []string{
+		"C: RCPT TO:<a@example.com>",
+		"C: RCPT TO:<b@example.com>",
+		"S: 550 5.1.1 Mailbox unavailable",
+	} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("expected transcript to contain %q, got:\n%s", want, transcript)
+		}
+	}
+}
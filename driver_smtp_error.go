@@ -0,0 +1,89 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"regexp"
+)
+
+// SMTP 会话阶段
+const (
+	SMTPPhaseConnect  = "connect"
+	SMTPPhaseGreeting = "greeting"
+	SMTPPhaseHello    = "hello"
+	SMTPPhaseStartTLS = "starttls"
+	SMTPPhaseAuth     = "auth"
+	SMTPPhaseMail     = "mail"
+	SMTPPhaseRcpt     = "rcpt"
+	SMTPPhaseData     = "data"
+)
+
+// SMTPError SMTP 服务器返回的结构化错误
+// 通过 errors.As 从组件错误中提取，用于按响应码分支处理（如 550 与 452）
+type SMTPError struct {
+	// Code SMTP 基础响应码（如 550）
+	Code int
+
+	// EnhancedCode RFC 3463 增强状态码（如 5.1.1），服务器未返回时为空
+	EnhancedCode string
+
+	// Message 服务器响应内容（不含增强状态码）
+	Message string
+
+	// Phase 出错的会话阶段
+	Phase string
+
+	err error
+}
+
+// Error 实现 error 接口
+func (e *SMTPError) Error() string {
+	if e.EnhancedCode != "" {
+		return fmt.Sprintf("smtp %s: %d %s %s", e.Phase, e.Code, e.EnhancedCode, e.Message)
+	}
+	return fmt.Sprintf("smtp %s: %d %s", e.Phase, e.Code, e.Message)
+}
+
+// Unwrap 返回原始错误
+func (e *SMTPError) Unwrap() error {
+	return e.err
+}
+
+// Temporary 是否为临时错误（4xx），可稍后重试
+func (e *SMTPError) Temporary() bool {
+	return e.Code >= 400 && e.Code < 500
+}
+
+// Permanent 是否为永久错误（5xx），重试无意义
+func (e *SMTPError) Permanent() bool {
+	return e.Code >= 500 && e.Code < 600
+}
+
+// newSMTPError 将 SMTP 协议错误转换为 SMTPError，非协议错误时返回 nil
+func newSMTPError(phase string, err error) *SMTPError {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		return nil
+	}
+	enhanced, text := parseEnhancedStatus(protoErr.Msg)
+	return &SMTPError{
+		Code:         protoErr.Code,
+		EnhancedCode: enhanced,
+		Message:      text,
+		Phase:        phase,
+		err:          err,
+	}
+}
+
+// enhancedStatusPattern RFC 3463 增强状态码: class.subject.detail
+var enhancedStatusPattern = regexp.MustCompile(`^([245]\.\d{1,3}\.\d{1,3})\s*`)
+
+// parseEnhancedStatus 从 SMTP 响应文本中拆分增强状态码和描述
+func parseEnhancedStatus(msg string) (string, string) {
+	m := enhancedStatusPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return "", msg
+	}
+	return m[1], msg[len(m[0]):]
+}
@@ -0,0 +1,121 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestParseEnhancedStatus(t *testing.T) {
+	tests := []struct {
+		msg          string
+		wantEnhanced string
+		wantText     string
+	}{
+		{"5.1.1 Mailbox unavailable", "5.1.1", "Mailbox unavailable"},
+		{"4.2.2 Over quota", "4.2.2", "Over quota"},
+		{"Mailbox unavailable", "", "Mailbox unavailable"},
+		{"1.2.3 not a status", "", "1.2.3 not a status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			enhanced, text := parseEnhancedStatus(tt.msg)
+			if enhanced != tt.wantEnhanced || text != tt.wantText {
+				t.Errorf("parseEnhancedStatus(%q) = %q, %q", tt.msg, enhanced, text)
+			}
+		})
+	}
+}
+
+func TestNewSMTPError(t *testing.T) {
+	err := newSMTPError(SMTPPhaseRcpt, &textproto.Error{Code: 452, Msg: "4.2.2 Over quota"})
+	if err == nil {
+		t.Fatal("expected SMTPError")
+	}
+	if err.Code != 452 || err.EnhancedCode != "4.2.2" || err.Message != "Over quota" || err.Phase != SMTPPhaseRcpt {
+		t.Errorf("unexpected SMTPError: %+v", err)
+	}
+	if !err.Temporary() || err.Permanent() {
+		t.Error("expected 452 to be temporary")
+	}
+
+	if newSMTPError(SMTPPhaseRcpt, errors.New("io error")) != nil {
+		t.Error("expected nil for non-protocol error")
+	}
+}
+
+func TestSMTPDriver_Send_SMTPErrorAs(t *testing.T) {
+	tests := []struct {
+		name      string
+		reject    func(cmd string) bool
+		reply     string
+		wantCode  int
+		wantPhase string
+		wantBase  error
+	}{
+		{
+			name:      "mailbox unavailable",
+			reject:    func(cmd string) bool { return strings.HasPrefix(cmd, "RCPT TO") },
+			reply:     "550 5.1.1 Mailbox unavailable",
+			wantCode:  550,
+			wantPhase: SMTPPhaseRcpt,
+			wantBase:  ErrSendFailed,
+		},
+		{
+			name:      "over quota on data",
+			reject:    func(cmd string) bool { return cmd == "DATA" },
+			reply:     "452 4.2.2 Over quota",
+			wantCode:  452,
+			wantPhase: SMTPPhaseData,
+			wantBase:  ErrSendFailed,
+		},
+		{
+			name:      "sender rejected",
+			reject:    func(cmd string) bool { return strings.HasPrefix(cmd, "MAIL FROM") },
+			reply:     "553 5.7.1 Sender not allowed",
+			wantCode:  553,
+			wantPhase: SMTPPhaseMail,
+			wantBase:  ErrSendFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startScriptedSMTPServer(t, func(cmd string) string {
+				if tt.reject(strings.ToUpper(cmd)) {
+					return tt.reply
+				}
+				return ""
+			})
+
+			driver, err := NewSMTPDriver(map[string]any{
+				"host": srv.host,
+				"port": srv.port,
+			})
+			if err != nil {
+				t.Fatalf("failed to create driver: %v", err)
+			}
+
+			_, err = driver.Send(context.Background(), &Message{
+				From:     "sender@example.com",
+				To:       []string{"to@example.com"},
+				Subject:  "Test",
+				BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantBase) {
+				t.Errorf("expected %v, got %v", tt.wantBase, err)
+			}
+
+			var smtpErr *SMTPError
+			if !errors.As(err, &smtpErr) {
+				t.Fatalf("expected SMTPError in chain, got %v", err)
+			}
+			if smtpErr.Code != tt.wantCode || smtpErr.Phase != tt.wantPhase {
+				t.Errorf("unexpected SMTPError: %+v", smtpErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package email
+
+import (
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTP 多主机选择策略
+const (
+	// SMTPHostOrdered 按配置顺序依次尝试（默认）
+	SMTPHostOrdered = "ordered"
+
+	// SMTPHostRandom 每次发送随机打乱顺序
+	SMTPHostRandom = "random"
+)
+
+// smtpHost SMTP 中继主机
+type smtpHost struct {
+	host string
+	port int
+
+	// socket Unix 套接字路径
+	socket string
+}
+
+// addr 获取 host:port 地址（Unix 套接字为 unix:///path）
+func (h smtpHost) addr() string {
+	if h.socket != "" {
+		return smtpUnixPrefix + h.socket
+	}
+	return net.JoinHostPort(h.host, strconv.Itoa(h.port))
+}
+
+// smtpHostPool SMTP 中继主机池
+// 连接失败的主机在冷却期内不再优先选择
+type smtpHostPool struct {
+	hosts     []smtpHost
+	selection string
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// newSMTPHostPool 根据配置创建主机池
+// 配置 Hosts 时使用 Hosts（未带端口的条目使用 Port），否则使用 Host:Port；unix:// 开头的条目为 Unix 套接字
+func newSMTPHostPool(cfg *SMTPConfig) (*smtpHostPool, error) {
+	entries := cfg.Hosts
+	if len(entries) == 0 && cfg.Host != "" {
+		entries = []string{cfg.Host}
+		if !strings.HasPrefix(cfg.Host, smtpUnixPrefix) {
+			entries[0] = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+		}
+	}
+
+	hosts := make([]smtpHost, 0, len(entries))
+	for _, entry := range entries {
+		if socket, ok := strings.CutPrefix(entry, smtpUnixPrefix); ok {
+			if socket == "" {
+				return nil, ErrDriverConfig.WithMsgf("SMTP 主机地址无效: %s", entry)
+			}
+			// 服务器名用于问候与 PLAIN 认证（localhost 允许未加密认证）
+			hosts = append(hosts, smtpHost{host: "localhost", socket: socket})
+			continue
+		}
+		host, portStr, err := net.SplitHostPort(entry)
+		if err != nil {
+			// 未带端口
+			hosts = append(hosts, smtpHost{host: entry, port: cfg.Port})
+			continue
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port <= 0 || host == "" {
+			return nil, ErrDriverConfig.WithMsgf("SMTP 主机地址无效: %s", entry)
+		}
+		hosts = append(hosts, smtpHost{host: host, port: port})
+	}
+
+	return &smtpHostPool{
+		hosts:     hosts,
+		selection: cfg.HostSelection,
+		cooldown:  cfg.HostCooldown,
+		until:     make(map[string]time.Time),
+	}, nil
+}
+
+// candidates 获取本次发送的尝试顺序
+// 冷却中的主机排在最后，保证全部冷却时仍有主机可用
+func (p *smtpHostPool) candidates() []smtpHost {
+	hosts := append([]smtpHost(nil), p.hosts...)
+	if p.selection == SMTPHostRandom {
+		rand.Shuffle(len(hosts), func(i, j int) {
+			hosts[i], hosts[j] = hosts[j], hosts[i]
+		})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	available := make([]smtpHost, 0, len(hosts))
+	var cooling []smtpHost
+	for _, h := range hosts {
+		if until, ok := p.until[h.addr()]; ok && now.Before(until) {
+			cooling = append(cooling, h)
+			continue
+		}
+		available = append(available, h)
+	}
+	return append(available, cooling...)
+}
+
+// markFailed 标记主机连接失败，进入冷却
+func (p *smtpHostPool) markFailed(h smtpHost) {
+	if p.cooldown <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.until[h.addr()] = time.Now().Add(p.cooldown)
+}
+
+// markHealthy 清除主机的冷却状态
+func (p *smtpHostPool) markHealthy(h smtpHost) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.until, h.addr())
+}
@@ -0,0 +1,199 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingDialer 记录拨号地址的拨号器
+type recordingDialer struct {
+	mu    sync.Mutex
+	addrs []string
+}
+
+func (d *recordingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.mu.Lock()
+	d.addrs = append(d.addrs, addr)
+	d.mu.Unlock()
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, addr)
+}
+
+func (d *recordingDialer) Addrs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.addrs...)
+}
+
+func TestNewSMTPDriver_Hosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantErr bool
+	}{
+		{
+			name: "string hosts",
+			config: map[string]any{
+				"hosts": []string{"smtp1.example.com:587", "smtp2.example.com"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "yaml hosts",
+			config: map[string]any{
+				"hosts":          []any{"smtp1.example.com:587", "smtp2.example.com:587"},
+				"host_selection": "random",
+				"host_cooldown":  "1m",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid port",
+			config: map[string]any{
+				"hosts": []string{"smtp1.example.com:abc"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid selection",
+			config: map[string]any{
+				"hosts":          []string{"smtp1.example.com:587"},
+				"host_selection": "weighted",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSMTPDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSMTPDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSMTPDriver_Send_HostFallbackAndCooldown(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+	port := strconv.Itoa(srv.port)
+	// 127.0.0.2 上没有监听，连接失败
+	badAddr := net.JoinHostPort("127.0.0.2", port)
+	goodAddr := net.JoinHostPort("127.0.0.1", port)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"hosts": []string{badAddr, goodAddr},
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	dialer := &recordingDialer{}
+	driver.(*SMTPDriver).SetDialer(dialer)
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	result, err := driver.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Host != goodAddr {
+		t.Errorf("expected delivery through %s, got %s", goodAddr, result.Host)
+	}
+
+	// 失败主机冷却中，第二次发送应直接使用可用主机
+	if _, err := driver.Send(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addrs := dialer.Addrs()
+	want := []string{badAddr, goodAddr, goodAddr}
+	if len(addrs) != len(want) {
+		t.Fatalf("expected dials %v, got %v", want, addrs)
+	}
+	for i := range want {
+		if addrs[i] != want[i] {
+			t.Errorf("expected dials %v, got %v", want, addrs)
+			break
+		}
+	}
+}
+
+func TestSMTPDriver_Send_HostReplyIsFinal(t *testing.T) {
+	// 第一个主机在握手阶段拒绝，服务器已响应，不应切换主机或进入冷却
+	refusing := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(cmd, "EHLO") || strings.HasPrefix(cmd, "HELO") {
+			return "554 5.7.1 Client host rejected"
+		}
+		return ""
+	})
+	good := startScriptedSMTPServer(t, nil)
+	refusingAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(refusing.port))
+	goodAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(good.port))
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"hosts": []string{refusingAddr, goodAddr},
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	dialer := &recordingDialer{}
+	driver.(*SMTPDriver).SetDialer(dialer)
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := driver.Send(context.Background(), msg)
+		var smtpErr *SMTPError
+		if !errors.As(err, &smtpErr) || smtpErr.Code != 554 {
+			t.Fatalf("expected 554 SMTPError, got %v", err)
+		}
+	}
+	addrs := dialer.Addrs()
+	if len(addrs) != 2 || addrs[0] != refusingAddr || addrs[1] != refusingAddr {
+		t.Errorf("expected only %s to be dialed, got %v", refusingAddr, addrs)
+	}
+}
+
+func TestSMTPHostPool_Candidates(t *testing.T) {
+	pool, err := newSMTPHostPool(&SMTPConfig{
+		Port:         25,
+		Hosts:        []string{"a.example.com:587", "b.example.com", "c.example.com:2525"},
+		HostCooldown: 30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pool.markFailed(smtpHost{host: "a.example.com", port: 587})
+	candidates := pool.candidates()
+	got := make([]string, len(candidates))
+	for i, h := range candidates {
+		got[i] = h.addr()
+	}
+	want := []string{"b.example.com:25", "c.example.com:2525", "a.example.com:587"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected candidates %v, got %v", want, got)
+		}
+	}
+
+	pool.markHealthy(smtpHost{host: "a.example.com", port: 587})
+	if first := pool.candidates()[0].addr(); first != "a.example.com:587" {
+		t.Errorf("expected healthy host first, got %s", first)
+	}
+}
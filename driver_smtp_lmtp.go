@@ -0,0 +1,108 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"net/smtp"
+)
+
+const (
+	// DriverLMTP LMTP 驱动名称（协议固定为 LMTP 的 SMTP 驱动）
+	DriverLMTP = "lmtp"
+)
+
+// SMTP 驱动协议
+const (
+	// SMTPProtocolSMTP SMTP 协议（默认）
+	SMTPProtocolSMTP = "smtp"
+
+	// SMTPProtocolLMTP LMTP 协议（RFC 2033），用于向 Dovecot、Postfix 等本地投递服务投递
+	SMTPProtocolLMTP = "lmtp"
+)
+
+// smtpUnixPrefix Unix 套接字地址前缀，如 unix:///var/run/dovecot/lmtp
+const smtpUnixPrefix = "unix://"
+
+// NewLMTPDriver 创建 LMTP 驱动
+// 配置与 SMTP 驱动相同，协议固定为 LMTP，TCP 端口默认 24
+func NewLMTPDriver(config map[string]any) (Driver, error) {
+	cfg := map[string]any{"port": 24}
+	maps.Copy(cfg, config)
+	cfg["protocol"] = SMTPProtocolLMTP
+	return NewSMTPDriver(cfg)
+}
+
+// lmtp 是否使用 LMTP 协议
+func (d *SMTPDriver) lmtp() bool {
+	return d.config.Protocol == SMTPProtocolLMTP
+}
+
+// attachLMTP 在 SMTP 客户端的文本连接上将问候命令改写为 LHLO
+// net/smtp 只会发送 EHLO（失败时 HELO），其余命令与 SMTP 相同
+func attachLMTP(client *smtp.Client) {
+	client.Text.Writer.W = bufio.NewWriter(&lmtpWriter{w: client.Text.Writer.W})
+}
+
+// lmtpWriter 将握手阶段的 EHLO/HELO 改写为 LHLO，握手完成后原样写入
+type lmtpWriter struct {
+	w    *bufio.Writer
+	done bool
+}
+
+// Write 实现 io.Writer（textproto 每条命令写入后立即刷新，命令总是位于写入的开头）
+func (w *lmtpWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if !w.done && (bytes.HasPrefix(p, []byte("EHLO ")) || bytes.HasPrefix(p, []byte("HELO "))) {
+		p = append([]byte("LHLO "), p[5:]...)
+	} else {
+		w.done = true
+	}
+	if _, err := w.w.Write(p); err != nil {
+		return 0, err
+	}
+	return n, w.w.Flush()
+}
+
+// lmtpDataReplies 结束数据流并读取 LMTP 逐个收件人的投递结果（RFC 2033 第 4.2 节）
+// 服务器按 RCPT TO 顺序为每个已接受的收件人返回一个响应，投递失败的收件人标记为未接受；
+// 邮件已投递给其余收件人，部分失败不作为错误返回
+func (d *SMTPDriver) lmtpDataReplies(ctx context.Context, client *smtp.Client, tr *smtpTranscript, wc io.WriteCloser, recipients []RecipientResult) ([]RecipientResult, error) {
+	first := true
+	for i := range recipients {
+		if !recipients[i].Accepted {
+			continue
+		}
+
+		// 第一个响应由数据流关闭时读取，其余响应依次读取
+		var err error
+		if first {
+			err = wc.Close()
+			first = false
+		} else {
+			_, _, err = client.Text.ReadResponse(250)
+		}
+		if err == nil {
+			continue
+		}
+
+		smtpErr := newSMTPError(SMTPPhaseData, err)
+		if smtpErr == nil {
+			return nil, d.sessionError(ctx, tr, ErrSendFailed, SMTPPhaseData, err, "读取 LMTP 投递结果失败")
+		}
+		recipients[i] = RecipientResult{
+			Email:        recipients[i].Email,
+			Code:         smtpErr.Code,
+			EnhancedCode: smtpErr.EnhancedCode,
+			Message:      smtpErr.Message,
+		}
+	}
+	return recipients, nil
+}
+
+func init() {
+	// 注册 LMTP 驱动到默认注册表
+	RegisterDriver(DriverLMTP, NewLMTPDriver)
+}
@@ -0,0 +1,317 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewLMTPDriver(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]any
+		wantPort int
+		wantErr  bool
+	}{
+		{
+			name:     "tcp default port",
+			config:   map[string]any{"host": "127.0.0.1"},
+			wantPort: 24,
+		},
+		{
+			name:     "unix socket",
+			config:   map[string]any{"host": "unix:///var/run/dovecot/lmtp"},
+			wantPort: 24,
+		},
+		{
+			name:     "port override",
+			config:   map[string]any{"host": "127.0.0.1", "port": 2424},
+			wantPort: 2424,
+		},
+		{
+			name:    "starttls",
+			config:  map[string]any{"host": "127.0.0.1", "security": "starttls"},
+			wantErr: true,
+		},
+		{
+			name:    "mx mode",
+			config:  map[string]any{"mode": "mx"},
+			wantErr: true,
+		},
+		{
+			name:    "empty socket path",
+			config:  map[string]any{"host": "unix://"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := NewLMTPDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLMTPDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			smtpDriver := driver.(*SMTPDriver)
+			if driver.Name() != DriverLMTP || smtpDriver.config.Protocol != SMTPProtocolLMTP {
+				t.Errorf("unexpected driver: %s %s", driver.Name(), smtpDriver.config.Protocol)
+			}
+			if smtpDriver.config.Port != tt.wantPort {
+				t.Errorf("expected port %d, got %d", tt.wantPort, smtpDriver.config.Port)
+			}
+		})
+	}
+
+	if _, err := NewSMTPDriver(map[string]any{"host": "127.0.0.1", "protocol": "ftp"}); err == nil {
+		t.Error("expected invalid protocol to be rejected")
+	}
+}
+
+// lmtpServer 监听 Unix 套接字的模拟 LMTP/SMTP 服务器
+type lmtpServer struct {
+	socket string
+
+	mu       sync.Mutex
+	commands []string
+	data     string
+}
+
+// Commands 获取服务器收到的命令
+func (s *lmtpServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// startLMTPServer 启动模拟服务器
+// rcptReply 返回 RCPT TO 的响应（空字符串为 250），dataReply 返回 DATA 结束后该收件人的响应；
+// dataReply 为 nil 时按 SMTP 只返回一个响应
+func startLMTPServer(t *testing.T, rcptReply, dataReply func(rcpt string) string) *lmtpServer {
+	t.Helper()
+
+	// Unix 套接字路径长度有限，不使用 t.TempDir
+	dir, err := os.MkdirTemp("", "lmtp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	srv := &lmtpServer{socket: filepath.Join(dir, "lmtp.sock")}
+	listener, err := net.Listen("unix", srv.socket)
+	if err != nil {
+		t.Fatalf("failed to start mock server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn, rcptReply, dataReply)
+		}
+	}()
+	return srv
+}
+
+func (s *lmtpServer) serve(conn net.Conn, rcptReply, dataReply func(rcpt string) string) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost LMTP ready")
+
+	var accepted []string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "LHLO"), strings.HasPrefix(cmd, "EHLO"):
+			tp.PrintfLine("250-localhost\r\n250-PIPELINING\r\n250 ENHANCEDSTATUSCODES")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			rcpt := strings.Trim(line[len("RCPT TO:"):], "<>")
+			reply := ""
+			if rcptReply != nil {
+				reply = rcptReply(rcpt)
+			}
+			if reply == "" {
+				accepted = append(accepted, rcpt)
+				reply = "250 2.1.5 OK"
+			}
+			tp.PrintfLine("%s", reply)
+		case cmd == "DATA":
+			tp.PrintfLine("354 OK")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			if dataReply == nil {
+				tp.PrintfLine("250 2.0.0 OK queued")
+				continue
+			}
+			for _, rcpt := range accepted {
+				tp.PrintfLine("%s", dataReply(rcpt))
+			}
+		case cmd == "QUIT":
+			tp.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			tp.PrintfLine("250 2.0.0 OK")
+		}
+	}
+}
+
+func TestLMTPDriver_Send_PerRecipientReplies(t *testing.T) {
+	srv := startLMTPServer(t,
+		func(rcpt string) string {
+			if rcpt == "unknown@example.com" {
+				return "550 5.1.1 <unknown@example.com> User doesn't exist"
+			}
+			return ""
+		},
+		func(rcpt string) string {
+			if rcpt == "full@example.com" {
+				return "452 4.2.2 <full@example.com> Mailbox is full"
+			}
+			return "250 2.0.0 <" + rcpt + "> Saved"
+		},
+	)
+
+	driver, err := NewLMTPDriver(map[string]any{
+		"host":             "unix://" + srv.socket,
+		"partial_delivery": true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"a@example.com", "unknown@example.com", "full@example.com"},
+		Bcc:      []string{"b@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success || result.Status != "partial" || result.Host != "unix://"+srv.socket {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	want := []RecipientResult{
+		{Email: "a@example.com", Accepted: true, Code: 250},
+		{Email: "unknown@example.com", Code: 550, EnhancedCode: "5.1.1", Message: "<unknown@example.com> User doesn't exist"},
+		{Email: "full@example.com", Code: 452, EnhancedCode: "4.2.2", Message: "<full@example.com> Mailbox is full"},
+		{Email: "b@example.com", Accepted: true, Code: 250},
+	}
+	if len(result.Recipients) != len(want) {
+		t.Fatalf("expected %d recipient results, got %+v", len(want), result.Recipients)
+	}
+	for i, r := range result.Recipients {
+		if r != want[i] {
+			t.Errorf("recipient %d: expected %+v, got %+v", i, want[i], r)
+		}
+	}
+
+	commands := srv.Commands()
+	if commands[0] != "LHLO localhost" {
+		t.Errorf("expected LHLO, got %q", commands[0])
+	}
+	if commands[len(commands)-1] != "QUIT" {
+		t.Errorf("expected session to end with QUIT after reading all replies, got %v", commands)
+	}
+}
+
+func TestLMTPDriver_Send_AllRejectedAfterData(t *testing.T) {
+	srv := startLMTPServer(t, nil, func(rcpt string) string {
+		return "552 5.2.2 <" + rcpt + "> Quota exceeded"
+	})
+
+	driver, _ := NewLMTPDriver(map[string]any{"host": "unix://" + srv.socket})
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Fatalf("expected ErrInvalidRecipient, got %v", err)
+	}
+	if result == nil || len(result.Recipients) != 2 {
+		t.Fatalf("expected recipient results, got %+v", result)
+	}
+	for _, r := range result.Recipients {
+		if r.Accepted || r.Code != 552 || r.EnhancedCode != "5.2.2" {
+			t.Errorf("unexpected recipient result: %+v", r)
+		}
+	}
+}
+
+func TestSMTPDriver_Send_UnixSocket(t *testing.T) {
+	srv := startLMTPServer(t, nil, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{"hosts": []any{"unix://" + srv.socket}})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	if driver.Name() != DriverSMTP {
+		t.Errorf("expected smtp driver, got %s", driver.Name())
+	}
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"a@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "sent" || result.Host != "unix://"+srv.socket {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if commands := srv.Commands(); commands[0] != "EHLO localhost" {
+		t.Errorf("expected EHLO, got %q", commands[0])
+	}
+}
+
+func TestLMTPDriver_Send_Transcript(t *testing.T) {
+	srv := startLMTPServer(t, func(rcpt string) string {
+		return "550 5.1.1 User doesn't exist"
+	}, nil)
+
+	driver, _ := NewLMTPDriver(map[string]any{"host": "unix://" + srv.socket, "debug": true})
+	_, err := driver.Send(context.Background(), &Message{
+		From: "sender@example.com", To: []string{"a@example.com"}, Subject: "Test", BodyText: "Hello",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if lines := strings.Join(SMTPTranscript(err), "\n"); !strings.Contains(lines, "C: LHLO localhost") {
+		t.Errorf("expected LHLO in transcript, got:\n%s", lines)
+	}
+}
+
+func TestDefaultRegistry_LMTP(t *testing.T) {
+	if !DefaultRegistry.Has(DriverLMTP) {
+		t.Error("expected lmtp driver to be registered")
+	}
+}
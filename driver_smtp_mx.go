@@ -0,0 +1,183 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+)
+
+// MXResolver MX 记录解析器（*net.Resolver 已实现）
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// SetMXResolver 设置 mx 模式使用的 MX 解析器（默认 net.DefaultResolver）
+func (d *SMTPDriver) SetMXResolver(resolver MXResolver) {
+	d.resolver = resolver
+}
+
+// sendDirect 按收件人域名查询 MX 记录直接投递
+// 每个域名按 MX 优先级依次尝试，使用机会性 STARTTLS；返回逐个收件人与逐个域名的结果
+// ctx 中途取消时返回已完成域名的结果与错误，调用方据此避免向已投递的域名重复发送
+func (d *SMTPDriver) sendDirect(ctx context.Context, msg *Message, body *smtpBody) ([]RecipientResult, []DomainResult, error) {
+	domains, groups, err := groupRecipientsByDomain(recipientsOf(msg))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 多个域名或 VERP 多个信封时邮件需要写入多次
+	if len(domains) > 1 || (msg.VERP && len(recipientsOf(msg)) > 1) {
+		if err := body.requireRereadable(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	recipients := make([]RecipientResult, 0, len(msg.To)+len(msg.Cc)+len(msg.Bcc))
+	domainResults := make([]DomainResult, 0, len(domains))
+	var lastErr error
+
+	for _, domain := range domains {
+		rcpts := groups[domain]
+		results, host, err := d.deliverDomain(ctx, msg, domain, rcpts, body)
+		if err != nil {
+			if ctx.Err() != nil {
+				return recipients, domainResults, err
+			}
+			lastErr = err
+			domainResults = append(domainResults, DomainResult{Domain: domain, Host: host, Message: err.Error()})
+			if len(results) == 0 {
+				results = failedRecipients(rcpts, err)
+			}
+			recipients = append(recipients, results...)
+			continue
+		}
+		domainResults = append(domainResults, DomainResult{Domain: domain, Host: host, Success: true})
+		recipients = append(recipients, results...)
+	}
+
+	for _, r := range recipients {
+		if r.Accepted {
+			return recipients, domainResults, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = ErrInvalidRecipient.WithMsg("所有收件人均被服务器拒绝")
+	}
+	return recipients, domainResults, lastErr
+}
+
+// deliverDomain 向单个域名投递，按 MX 优先级依次尝试
+// 连接失败或临时错误（4xx）时尝试下一个主机，永久错误直接返回
+func (d *SMTPDriver) deliverDomain(ctx context.Context, msg *Message, domain string, rcpts []string, body *smtpBody) ([]RecipientResult, string, error) {
+	hosts, err := d.lookupMX(ctx, domain)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lastErr error
+	var lastHost string
+	var lastResults []RecipientResult
+	for _, host := range hosts {
+		endpoint := smtpEndpoint{
+			host:     host,
+			port:     d.config.Port,
+			security: smtpSecurityOpportunistic,
+		}
+		results, err := d.session(ctx, endpoint, msg, envelopesOf(msg, rcpts), body)
+		if err == nil {
+			return results, host, nil
+		}
+		lastErr, lastHost, lastResults = err, host, results
+		if ctx.Err() != nil || !retryableOnNextMX(err) {
+			break
+		}
+	}
+	// 服务器逐个拒绝收件人时（部分投递模式）保留逐个收件人的结果
+	return lastResults, lastHost, lastErr
+}
+
+// failedRecipients 为投递失败的域名生成逐个收件人结果，保留服务器返回的响应码
+func failedRecipients(rcpts []string, err error) []RecipientResult {
+	result := RecipientResult{Message: err.Error()}
+	var smtpErr *SMTPError
+	if errors.As(err, &smtpErr) {
+		result.Code = smtpErr.Code
+		result.EnhancedCode = smtpErr.EnhancedCode
+		result.Message = smtpErr.Message
+	}
+
+	results := make([]RecipientResult, 0, len(rcpts))
+	for _, rcpt := range rcpts {
+		result.Email = rcpt
+		results = append(results, result)
+	}
+	return results
+}
+
+// lookupMX 查询域名的 MX 主机（按优先级排序）
+// 没有 MX 记录时按 RFC 5321 回退到域名本身
+func (d *SMTPDriver) lookupMX(ctx context.Context, domain string) ([]string, error) {
+	asciiDomain, err := toASCIIDomain(domain)
+	if err != nil {
+		return nil, ErrInvalidRecipient.Wrap(err).WithMsgf("国际化域名转换失败: %s", domain)
+	}
+
+	records, err := d.resolver.LookupMX(ctx, asciiDomain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			if ctx.Err() != nil {
+				return nil, ErrTimeout.Wrap(err).WithMsgf("查询 MX 记录超时: %s", domain)
+			}
+			return nil, ErrConnectionFailed.Wrap(err).WithMsgf("查询 MX 记录失败: %s", domain)
+		}
+	}
+
+	if len(records) == 0 {
+		return []string{asciiDomain}, nil
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Pref < records[j].Pref
+	})
+	hosts := make([]string, 0, len(records))
+	for _, mx := range records {
+		host := strings.TrimSuffix(mx.Host, ".")
+		// 空 MX（"."）表示域名不接收邮件（RFC 7505）
+		if host == "" {
+			return nil, ErrInvalidRecipient.WithMsgf("域名不接收邮件: %s", domain)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// retryableOnNextMX 错误是否应尝试下一个 MX 主机
+func retryableOnNextMX(err error) bool {
+	var smtpErr *SMTPError
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Temporary()
+	}
+	return errors.Is(err, ErrConnectionFailed)
+}
+
+// groupRecipientsByDomain 按域名（不区分大小写）分组收件人，保持首次出现的顺序
+// 无法解析出域名的地址返回 ErrInvalidRecipient
+func groupRecipientsByDomain(recipients []string) ([]string, map[string][]string, error) {
+	var domains []string
+	groups := make(map[string][]string)
+	for _, rcpt := range recipients {
+		_, domain, ok := splitAddress(rcpt)
+		if !ok {
+			return nil, nil, ErrInvalidRecipient.WithMsgf("收件人地址无效: %s", rcpt)
+		}
+		domain = strings.ToLower(domain)
+		if _, exists := groups[domain]; !exists {
+			domains = append(domains, domain)
+		}
+		groups[domain] = append(groups[domain], rcpt)
+	}
+	return domains, groups, nil
+}
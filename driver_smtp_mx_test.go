@@ -0,0 +1,294 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeMXResolver 模拟 MX 解析器
+type fakeMXResolver struct {
+	records map[string][]*net.MX
+	errs    map[string]error
+}
+
+func (r *fakeMXResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if err, ok := r.errs[name]; ok {
+		return nil, err
+	}
+	return r.records[name], nil
+}
+
+func newMXDriver(t *testing.T, port int, resolver MXResolver) *SMTPDriver {
+	driver, err := NewSMTPDriver(map[string]any{
+		"mode": SMTPModeMX,
+		"port": port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	smtpDriver := driver.(*SMTPDriver)
+	smtpDriver.SetMXResolver(resolver)
+	return smtpDriver
+}
+
+func TestNewSMTPDriver_MXMode(t *testing.T) {
+	if _, err := NewSMTPDriver(map[string]any{"mode": SMTPModeMX}); err != nil {
+		t.Errorf("expected mx mode without host to be valid, got %v", err)
+	}
+	if _, err := NewSMTPDriver(map[string]any{"mode": "unknown", "host": "smtp.example.com"}); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestSMTPDriver_Send_MX(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	resolver := &fakeMXResolver{
+		records: map[string][]*net.MX{
+			"example.com": {{Host: "127.0.0.1.", Pref: 10}},
+		},
+		errs: map[string]error{
+			"broken.example": errors.New("dns server failure"),
+		},
+	}
+	driver := newMXDriver(t, srv.port, resolver)
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "alerts@ours.com",
+		To:       []string{"ops@example.com", "dev@broken.example", "sre@EXAMPLE.com"},
+		Subject:  "Alert",
+		BodyText: "Disk full",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "partial" {
+		t.Errorf("expected status 'partial', got '%s'", result.Status)
+	}
+	if len(result.Domains) != 2 {
+		t.Fatalf("expected 2 domain results, got %d", len(result.Domains))
+	}
+
+	ok, failed := result.Domains[0], result.Domains[1]
+	if ok.Domain != "example.com" || !ok.Success || ok.Host != "127.0.0.1" {
+		t.Errorf("unexpected domain result: %+v", ok)
+	}
+	if failed.Domain != "broken.example" || failed.Success || failed.Message == "" {
+		t.Errorf("unexpected domain result: %+v", failed)
+	}
+
+	accepted := 0
+	for _, r := range result.Recipients {
+		if r.Accepted {
+			accepted++
+		}
+	}
+	if accepted != 2 {
+		t.Errorf("expected 2 accepted recipients, got %d", accepted)
+	}
+}
+
+func TestSMTPDriver_Send_MX_Fallback(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	resolver := &fakeMXResolver{
+		records: map[string][]*net.MX{
+			// 127.0.0.2 上没有监听，连接失败后应回退到下一个主机
+			"example.com": {
+				{Host: "127.0.0.1.", Pref: 20},
+				{Host: "127.0.0.2.", Pref: 5},
+			},
+		},
+	}
+	driver := newMXDriver(t, srv.port, resolver)
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "alerts@ours.com",
+		To:       []string{"ops@example.com"},
+		Subject:  "Alert",
+		BodyText: "Disk full",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Domains) != 1 || result.Domains[0].Host != "127.0.0.1" {
+		t.Errorf("expected delivery through fallback host, got %+v", result.Domains)
+	}
+}
+
+func TestSMTPDriver_Send_MX_ImplicitMX(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	resolver := &fakeMXResolver{
+		errs: map[string]error{
+			"127.0.0.1": &net.DNSError{Err: "no such host", Name: "127.0.0.1", IsNotFound: true},
+		},
+	}
+	driver := newMXDriver(t, srv.port, resolver)
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "alerts@ours.com",
+		To:       []string{"ops@127.0.0.1"},
+		Subject:  "Alert",
+		BodyText: "Disk full",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Domains[0].Host != "127.0.0.1" {
+		t.Errorf("expected delivery to domain itself, got %+v", result.Domains)
+	}
+}
+
+func TestSMTPDriver_Send_MX_PermanentFailure(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(cmd, "RCPT TO") {
+			return "550 5.1.1 No such user"
+		}
+		return ""
+	})
+
+	resolver := &fakeMXResolver{
+		records: map[string][]*net.MX{
+			"example.com": {{Host: "127.0.0.1.", Pref: 10}},
+		},
+	}
+	driver := newMXDriver(t, srv.port, resolver)
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "alerts@ours.com",
+		To:       []string{"ops@example.com"},
+		Subject:  "Alert",
+		BodyText: "Disk full",
+	})
+	if !errors.Is(err, ErrSendFailed) {
+		t.Fatalf("expected ErrSendFailed, got %v", err)
+	}
+	if result == nil || len(result.Domains) != 1 || result.Domains[0].Success {
+		t.Fatalf("expected failed domain result, got %+v", result)
+	}
+	if len(result.Recipients) != 1 {
+		t.Fatalf("expected 1 recipient result, got %+v", result.Recipients)
+	}
+	r := result.Recipients[0]
+	if r.Code != 550 || r.EnhancedCode != "5.1.1" || r.Message != "No such user" {
+		t.Errorf("expected SMTP reply on recipient result, got %+v", r)
+	}
+}
+
+// cancelMXResolver 查询指定域名时取消 ctx，模拟发送中途被取消
+type cancelMXResolver struct {
+	fakeMXResolver
+	cancelOn string
+	cancel   context.CancelFunc
+}
+
+func (r *cancelMXResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if name == r.cancelOn {
+		r.cancel()
+		return nil, ctx.Err()
+	}
+	return r.fakeMXResolver.LookupMX(ctx, name)
+}
+
+func TestSMTPDriver_Send_MX_CancelKeepsPartialResults(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resolver := &cancelMXResolver{
+		fakeMXResolver: fakeMXResolver{
+			records: map[string][]*net.MX{
+				"example.com": {{Host: "127.0.0.1.", Pref: 10}},
+			},
+		},
+		cancelOn: "slow.example",
+		cancel:   cancel,
+	}
+	driver := newMXDriver(t, srv.port, resolver)
+
+	result, err := driver.Send(ctx, &Message{
+		From:     "alerts@ours.com",
+		To:       []string{"ops@example.com", "dev@slow.example"},
+		Subject:  "Alert",
+		BodyText: "Disk full",
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if result == nil || len(result.Domains) != 1 || !result.Domains[0].Success || result.Domains[0].Domain != "example.com" {
+		t.Fatalf("expected delivered domain to be reported, got %+v", result)
+	}
+	if len(result.Recipients) != 1 || !result.Recipients[0].Accepted {
+		t.Errorf("expected accepted recipient to be reported, got %+v", result.Recipients)
+	}
+}
+
+func TestSMTPDriver_Send_MX_InvalidRecipient(t *testing.T) {
+	resolver := &fakeMXResolver{}
+	driver := newMXDriver(t, 25, resolver)
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "alerts@ours.com",
+		To:       []string{"ops@example.com", "not-an-address"},
+		Subject:  "Alert",
+		BodyText: "Disk full",
+	})
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Errorf("expected ErrInvalidRecipient, got %v", err)
+	}
+}
+
+func TestSMTPDriver_Send_MX_NonSeekableReader(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	resolver := &fakeMXResolver{
+		records: map[string][]*net.MX{
+			"example.com": {{Host: "127.0.0.1.", Pref: 10}},
+			"example.org": {{Host: "127.0.0.1.", Pref: 10}},
+		},
+	}
+	driver := newMXDriver(t, srv.port, resolver)
+
+	// 多个域名需要写入多次，只能读取一次的 Reader 在投递前拒绝
+	_, err := driver.Send(context.Background(), &Message{
+		From:     "alerts@ours.com",
+		To:       []string{"ops@example.com", "dev@example.org"},
+		Subject:  "Alert",
+		BodyText: "Disk full",
+		Attachments: []Attachment{{
+			Filename:    "r.txt",
+			ContentType: "text/plain",
+			Reader:      io.MultiReader(strings.NewReader("data")),
+		}},
+	})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+	if cmds := srv.Commands(); len(cmds) != 0 {
+		t.Errorf("expected no SMTP session, got %v", cmds)
+	}
+}
+
+func TestGroupRecipientsByDomain(t *testing.T) {
+	domains, groups, err := groupRecipientsByDomain([]string{"a@x.com", "b@y.com", "c@X.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(domains) != 2 || domains[0] != "x.com" || domains[1] != "y.com" {
+		t.Errorf("unexpected domains: %v", domains)
+	}
+	if len(groups["x.com"]) != 2 {
+		t.Errorf("expected 2 recipients for x.com, got %v", groups["x.com"])
+	}
+}
+
+func TestGroupRecipientsByDomain_Invalid(t *testing.T) {
+	if _, _, err := groupRecipientsByDomain([]string{"a@x.com", "nobody"}); !errors.Is(err, ErrInvalidRecipient) {
+		t.Errorf("expected ErrInvalidRecipient, got %v", err)
+	}
+}
@@ -1,9 +1,18 @@
 package email
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"os"
+	"path/filepath"
+	"net/textproto"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -129,13 +138,6 @@ func TestSMTPDriver_Validate(t *testing.T) {
 }
 
 func TestSMTPDriver_buildEmailBody(t *testing.T) {
-	driver := &SMTPDriver{
-		config: &SMTPConfig{
-			Host: "smtp.example.com",
-			Port: 587,
-		},
-	}
-
 	tests := []struct {
 		name     string
 		msg      *Message
@@ -263,7 +265,7 @@ func TestSMTPDriver_buildEmailBody(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			body := driver.buildEmailBody(tt.msg)
+			body := renderSMTPBody(t, tt.msg)
 			for _, s := range tt.contains {
 				if !strings.Contains(body, s) {
 					t.Errorf("expected body to contain %q, got:\n%s", s, body)
@@ -273,6 +275,20 @@ func TestSMTPDriver_buildEmailBody(t *testing.T) {
 	}
 }
 
+// renderSMTPBody 渲染完整邮件内容
+func renderSMTPBody(t *testing.T, msg *Message) string {
+	t.Helper()
+	body, err := newSMTPBody(msg)
+	if err != nil {
+		t.Fatalf("failed to build body: %v", err)
+	}
+	var buf strings.Builder
+	if _, err := body.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write body: %v", err)
+	}
+	return buf.String()
+}
+
 func TestSMTPDriver_Send_InvalidMessage(t *testing.T) {
 	driver, _ := NewSMTPDriver(map[string]any{
 		"host": "smtp.example.com",
@@ -436,3 +452,946 @@ func TestDefaultRegistry_SMTP(t *testing.T) {
 		t.Error("expected DefaultRegistry to have SMTP driver")
 	}
 }
+
+// startStalledSMTPServer 启动一个只发送欢迎消息后不再响应的模拟 SMTP 服务器
+func startStalledSMTPServer(t *testing.T) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start mock server: %v", err)
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("220 Mock SMTP Server\r\n"))
+			go func() {
+				<-done
+				conn.Close()
+			}()
+		}
+	}()
+
+	tcpAddr := listener.Addr().(*net.TCPAddr)
+	return tcpAddr.IP.String(), tcpAddr.Port
+}
+
+func TestSMTPDriver_Send_ContextDeadline(t *testing.T) {
+	host, port := startStalledSMTPServer(t)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": host,
+		"port": port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = driver.Send(ctx, msg)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected send to abort near deadline, took %v", elapsed)
+	}
+}
+
+func TestSMTPDriver_Send_ContextCanceled(t *testing.T) {
+	host, port := startStalledSMTPServer(t)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": host,
+		"port": port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := driver.Send(ctx, msg)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("expected ErrTimeout, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("send did not abort after context cancellation")
+	}
+}
+
+// pendingDeadlineContext 截止时间已到但自身计时器尚未触发的 ctx
+// 模拟连接读写超时（与 ctx 截止时间相同）先于 ctx 计时器触发的情况
+type pendingDeadlineContext struct {
+	context.Context
+	deadline time.Time
+}
+
+// Deadline 返回截止时间
+func (c pendingDeadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func TestSMTPDriver_Send_ConnDeadlineBeforeContextTimer(t *testing.T) {
+	host, port := startStalledSMTPServer(t)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": host,
+		"port": port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	// Err() 始终为 nil，只有连接截止时间生效
+	ctx := pendingDeadlineContext{Context: context.Background(), deadline: time.Now().Add(200 * time.Millisecond)}
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"recipient@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	_, err = driver.Send(ctx, msg)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout when the connection hits the ctx deadline, got %v", err)
+	}
+}
+
+// scriptedSMTPServer 按行解析命令的模拟 SMTP 服务器，可通过 handler 定制响应
+type scriptedSMTPServer struct {
+	host string
+	port int
+
+	mu       sync.Mutex
+	commands []string
+	data     string
+}
+
+// Commands 获取服务器收到的命令
+func (s *scriptedSMTPServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Data 获取服务器收到的邮件内容
+func (s *scriptedSMTPServer) Data() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data
+}
+
+// startScriptedSMTPServer 启动模拟 SMTP 服务器
+// handler 返回空字符串时使用默认响应
+func startScriptedSMTPServer(t *testing.T, handler func(cmd string) string) *scriptedSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start mock server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	tcpAddr := listener.Addr().(*net.TCPAddr)
+	srv := &scriptedSMTPServer{host: tcpAddr.IP.String(), port: tcpAddr.Port}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn, handler)
+		}
+	}()
+
+	return srv
+}
+
+func (s *scriptedSMTPServer) serve(conn net.Conn, handler func(cmd string) string) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 Mock SMTP Server")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		reply := ""
+		if handler != nil {
+			reply = handler(line)
+		}
+		if reply == "" {
+			reply = defaultSMTPReply(line)
+		}
+		tp.PrintfLine("%s", reply)
+
+		cmd := strings.ToUpper(line)
+		if cmd == "DATA" && strings.HasPrefix(reply, "354") {
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK queued")
+		}
+		if cmd == "QUIT" {
+			return
+		}
+	}
+}
+
+// defaultSMTPReply 默认的 SMTP 响应
+func defaultSMTPReply(line string) string {
+	cmd := strings.ToUpper(line)
+	switch {
+	case strings.HasPrefix(cmd, "EHLO"):
+		return "250-localhost\r\n250 OK"
+	case strings.HasPrefix(cmd, "HELO"):
+		return "250 localhost"
+	case cmd == "DATA":
+		return "354 Start mail input"
+	case cmd == "QUIT":
+		return "221 Bye"
+	default:
+		return "250 OK"
+	}
+}
+
+func TestSMTPDriver_Send_PartialDelivery(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.Contains(cmd, "<bad@example.com>") {
+			return "550 5.1.1 Mailbox unavailable"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":             srv.host,
+		"port":             srv.port,
+		"partial_delivery": true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"good@example.com", "bad@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	result, err := driver.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "partial" {
+		t.Errorf("expected status 'partial', got '%s'", result.Status)
+	}
+	if len(result.Recipients) != 2 {
+		t.Fatalf("expected 2 recipient results, got %d", len(result.Recipients))
+	}
+	if !result.Recipients[0].Accepted {
+		t.Error("expected good@example.com to be accepted")
+	}
+	rejected := result.Recipients[1]
+	if rejected.Accepted || rejected.Code != 550 || rejected.EnhancedCode != "5.1.1" {
+		t.Errorf("unexpected rejected recipient result: %+v", rejected)
+	}
+	if rejected.Message != "Mailbox unavailable" {
+		t.Errorf("expected message 'Mailbox unavailable', got '%s'", rejected.Message)
+	}
+	if srv.Data() == "" {
+		t.Error("expected message to be delivered to accepted recipient")
+	}
+}
+
+func TestSMTPDriver_Send_PartialDelivery_AllRejected(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "RCPT TO") {
+			return "550 5.1.1 Mailbox unavailable"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":             srv.host,
+		"port":             srv.port,
+		"partial_delivery": true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"bad@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	result, err := driver.Send(context.Background(), msg)
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Fatalf("expected ErrInvalidRecipient, got %v", err)
+	}
+	if result == nil || len(result.Recipients) != 1 || result.Recipients[0].Accepted {
+		t.Errorf("expected rejected recipient in result, got %+v", result)
+	}
+}
+
+func TestSMTPDriver_Send_RejectedRecipientAborts(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.Contains(cmd, "<bad@example.com>") {
+			return "550 5.1.1 Mailbox unavailable"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": srv.host,
+		"port": srv.port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"good@example.com", "bad@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	}
+
+	if _, err := driver.Send(context.Background(), msg); !errors.Is(err, ErrSendFailed) {
+		t.Errorf("expected ErrSendFailed, got %v", err)
+	}
+	if srv.Data() != "" {
+		t.Error("expected message not to be delivered")
+	}
+}
+
+func TestSMTPDriver_Send_SMTPUTF8(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "EHLO") {
+			return "250-localhost\r\n250 SMTPUTF8"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": srv.host,
+		"port": srv.port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"用户@例子.公司"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	commands := strings.Join(srv.Commands(), "\n")
+	if !strings.Contains(commands, "MAIL FROM:<sender@example.com> SMTPUTF8") {
+		t.Errorf("expected SMTPUTF8 parameter, got:\n%s", commands)
+	}
+	if !strings.Contains(commands, "RCPT TO:<用户@例子.公司>") {
+		t.Errorf("expected UTF-8 recipient, got:\n%s", commands)
+	}
+}
+
+func TestSMTPDriver_Send_ASCIIWithoutMailParams(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "EHLO") {
+			return "250-localhost\r\n250-8BITMIME\r\n250 SMTPUTF8"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": srv.host,
+		"port": srv.port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	// 纯 ASCII 信封与邮件头、正文为 base64，不应声明 SMTPUTF8 与 BODY=8BITMIME
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"user@example.com"},
+		Subject:  "测试",
+		BodyText: "你好",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	commands := srv.Commands()
+	found := false
+	for _, cmd := range commands {
+		if strings.HasPrefix(cmd, "MAIL FROM:") {
+			found = true
+			if cmd != "MAIL FROM:<sender@example.com>" {
+				t.Errorf("expected bare MAIL FROM, got %q", cmd)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected MAIL FROM command, got:\n%s", strings.Join(commands, "\n"))
+	}
+}
+
+func TestSMTPDriver_Send_UTF8HeaderUsesSMTPUTF8(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "EHLO") {
+			return "250-localhost\r\n250-8BITMIME\r\n250 SMTPUTF8"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": srv.host,
+		"port": srv.port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	// 附件文件名未编码，邮件头含 UTF-8 字符
+	_, err = driver.Send(context.Background(), &Message{
+		From:        "sender@example.com",
+		To:          []string{"user@example.com"},
+		Subject:     "Test",
+		BodyText:    "Hello",
+		Attachments: []Attachment{{Filename: "报告.txt", Content: []byte("data"), ContentType: "text/plain"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	commands := strings.Join(srv.Commands(), "\n")
+	if !strings.Contains(commands, "MAIL FROM:<sender@example.com> BODY=8BITMIME SMTPUTF8") {
+		t.Errorf("expected BODY=8BITMIME and SMTPUTF8 parameters, got:\n%s", commands)
+	}
+}
+
+func TestSMTPDriver_Send_IDNWithoutSMTPUTF8(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": srv.host,
+		"port": srv.port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"user@例子.公司"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	commands := strings.Join(srv.Commands(), "\n")
+	if !strings.Contains(commands, "RCPT TO:<user@xn--fsqu00a.xn--55qx5d>") {
+		t.Errorf("expected punycode recipient, got:\n%s", commands)
+	}
+	if !strings.Contains(srv.Data(), "To: user@xn--fsqu00a.xn--55qx5d") {
+		t.Errorf("expected punycode To header, got:\n%s", srv.Data())
+	}
+}
+
+func TestSMTPDriver_Send_NonASCIILocalPartWithoutSMTPUTF8(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": srv.host,
+		"port": srv.port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"用户@例子.公司"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrInvalidRecipient) {
+		t.Errorf("expected ErrInvalidRecipient, got %v", err)
+	}
+	if srv.Data() != "" {
+		t.Error("expected message not to be delivered")
+	}
+}
+
+func TestSMTPDriver_Send_DSN(t *testing.T) {
+	msg := &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com", "legal@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+		DSN: &DSNOptions{
+			Notify: []string{DSNNotifyFailure, DSNNotifyDelay},
+			RecipientNotify: map[string][]string{
+				"legal@example.com": {DSNNotifySuccess, DSNNotifyFailure},
+			},
+			Return:     DSNReturnHeaders,
+			EnvelopeID: "contract 42",
+		},
+	}
+
+	t.Run("advertised", func(t *testing.T) {
+		srv := startScriptedSMTPServer(t, func(cmd string) string {
+			if strings.HasPrefix(strings.ToUpper(cmd), "EHLO") {
+				return "250-localhost\r\n250 DSN"
+			}
+			return ""
+		})
+
+		driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+		if err != nil {
+			t.Fatalf("failed to create driver: %v", err)
+		}
+		if _, err := driver.Send(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		commands := strings.Join(srv.Commands(), "\n")
+		for _, want := range []string{
+			"MAIL FROM:<sender@example.com> RET=HDRS ENVID=contract+2042",
+			"RCPT TO:<to@example.com> NOTIFY=FAILURE,DELAY",
+			"RCPT TO:<legal@example.com> NOTIFY=SUCCESS,FAILURE",
+		} {
+			if !strings.Contains(commands, want) {
+				t.Errorf("expected command %q, got:\n%s", want, commands)
+			}
+		}
+	})
+
+	t.Run("not advertised", func(t *testing.T) {
+		srv := startScriptedSMTPServer(t, nil)
+
+		driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+		if err != nil {
+			t.Fatalf("failed to create driver: %v", err)
+		}
+		if _, err := driver.Send(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		commands := strings.Join(srv.Commands(), "\n")
+		if strings.Contains(commands, "NOTIFY=") || strings.Contains(commands, "RET=") {
+			t.Errorf("expected no DSN parameters, got:\n%s", commands)
+		}
+	})
+}
+
+func TestSMTPDriver_Send_EnvelopeFrom(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:         "noreply@example.com",
+		EnvelopeFrom: "bounces@ours.com",
+		To:           []string{"to@example.com"},
+		Subject:      "Test",
+		BodyText:     "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	commands := strings.Join(srv.Commands(), "\n")
+	if !strings.Contains(commands, "MAIL FROM:<bounces@ours.com>") {
+		t.Errorf("expected envelope sender, got:\n%s", commands)
+	}
+	if !strings.Contains(srv.Data(), "From: noreply@example.com") {
+		t.Errorf("expected From header to be unchanged, got:\n%s", srv.Data())
+	}
+}
+
+func TestSMTPDriver_Send_VERP(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:         "noreply@example.com",
+		EnvelopeFrom: "bounces@ours.com",
+		VERP:         true,
+		To:           []string{"user@example.com", "other@example.org"},
+		Subject:      "Test",
+		BodyText:     "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Recipients) != 2 {
+		t.Errorf("expected 2 recipient results, got %d", len(result.Recipients))
+	}
+
+	commands := srv.Commands()
+	var mails []string
+	for _, cmd := range commands {
+		if strings.HasPrefix(cmd, "MAIL FROM") {
+			mails = append(mails, cmd)
+		}
+	}
+	want := []string{
+		"MAIL FROM:<bounces+user=example.com@ours.com>",
+		"MAIL FROM:<bounces+other=example.org@ours.com>",
+	}
+	if strings.Join(mails, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected VERP envelopes %v, got %v", want, mails)
+	}
+}
+
+func TestSMTPDriver_Send_VERPNonSeekableReader(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	// 只能读取一次的 Reader 无法写入多个信封，需要在第一个 MAIL FROM 之前拒绝
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "bounces@ours.com",
+		VERP:     true,
+		To:       []string{"user@example.com", "other@example.org"},
+		Subject:  "Test",
+		BodyText: "Hello",
+		Attachments: []Attachment{{
+			Filename:    "r.txt",
+			ContentType: "text/plain",
+			Reader:      io.MultiReader(strings.NewReader("data")),
+		}},
+	})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+	for _, cmd := range srv.Commands() {
+		if strings.HasPrefix(cmd, "MAIL FROM") {
+			t.Errorf("expected no MAIL FROM, got %q", cmd)
+		}
+	}
+}
+
+func TestSMTPDriver_Send_VERPSecondRecipientRejected(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(cmd, "RCPT TO:<other@example.org>") {
+			return "550 5.1.1 User unknown"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "bounces@ours.com",
+		VERP:     true,
+		To:       []string{"user@example.com", "other@example.org"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrSendFailed) {
+		t.Fatalf("expected ErrSendFailed, got %v", err)
+	}
+	if srv.Data() == "" {
+		t.Fatal("expected first recipient to be delivered")
+	}
+
+	// 第一个信封已投递，需要返回逐个收件人结果，避免调用方重试时重复发送
+	if result == nil || len(result.Recipients) != 2 {
+		t.Fatalf("expected 2 recipient results, got %+v", result)
+	}
+	if r := result.Recipients[0]; r.Email != "user@example.com" || !r.Accepted {
+		t.Errorf("expected first recipient accepted, got %+v", r)
+	}
+	if r := result.Recipients[1]; r.Email != "other@example.org" || r.Accepted || r.Code != 550 || r.EnhancedCode != "5.1.1" {
+		t.Errorf("expected second recipient rejected with 550 5.1.1, got %+v", r)
+	}
+}
+
+func TestSMTPDriver_Send_MaxMessageSize(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":             srv.host,
+		"port":             srv.port,
+		"max_message_size": 64,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "from@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: strings.Repeat("x", 128),
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+	if len(srv.Commands()) != 0 {
+		t.Errorf("expected no connection before size check, got %v", srv.Commands())
+	}
+}
+
+func TestSMTPDriver_Send_SIZEExtension(t *testing.T) {
+	ehlo := func(limit string) func(cmd string) string {
+		return func(cmd string) string {
+			if strings.HasPrefix(strings.ToUpper(cmd), "EHLO") {
+				return "250-localhost\r\n250 SIZE " + limit
+			}
+			return ""
+		}
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		srv := startScriptedSMTPServer(t, ehlo("10240"))
+		driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+		if err != nil {
+			t.Fatalf("failed to create driver: %v", err)
+		}
+
+		_, err = driver.Send(context.Background(), &Message{
+			From:     "from@example.com",
+			To:       []string{"to@example.com"},
+			Subject:  "Test",
+			BodyText: "Hello",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		commands := strings.Join(srv.Commands(), "\n")
+		if !strings.Contains(commands, "MAIL FROM:<from@example.com> SIZE=") {
+			t.Errorf("expected SIZE parameter, got:\n%s", commands)
+		}
+	})
+
+	t.Run("exceeds server limit", func(t *testing.T) {
+		srv := startScriptedSMTPServer(t, ehlo("100"))
+		driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+		if err != nil {
+			t.Fatalf("failed to create driver: %v", err)
+		}
+
+		_, err = driver.Send(context.Background(), &Message{
+			From:     "from@example.com",
+			To:       []string{"to@example.com"},
+			Subject:  "Test",
+			BodyText: strings.Repeat("x", 200),
+		})
+		if !errors.Is(err, ErrMessageTooLarge) {
+			t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+		}
+		for _, cmd := range srv.Commands() {
+			if strings.HasPrefix(cmd, "MAIL FROM") {
+				t.Errorf("expected no MAIL FROM after size check, got %q", cmd)
+			}
+		}
+	})
+
+	t.Run("no limit advertised", func(t *testing.T) {
+		srv := startScriptedSMTPServer(t, ehlo("0"))
+		driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+		if err != nil {
+			t.Fatalf("failed to create driver: %v", err)
+		}
+
+		_, err = driver.Send(context.Background(), &Message{
+			From:     "from@example.com",
+			To:       []string{"to@example.com"},
+			Subject:  "Test",
+			BodyText: strings.Repeat("x", 200),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSMTPDriver_Send_StreamingAttachments(t *testing.T) {
+	srv := startScriptedSMTPServer(t, func(cmd string) string {
+		if strings.HasPrefix(strings.ToUpper(cmd), "EHLO") {
+			return "250-localhost\r\n250 SIZE 10485760"
+		}
+		return ""
+	})
+
+	driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	fileContent := bytes.Repeat([]byte("0123456789"), 1000)
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, fileContent, 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	msg := &Message{
+		From:     "from@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+		Attachments: []Attachment{
+			{Filename: "export.csv", Path: path},
+			{Filename: "stream.txt", Reader: strings.NewReader("streamed content")},
+		},
+	}
+	body, err := newSMTPBody(msg)
+	if err != nil {
+		t.Fatalf("failed to build body: %v", err)
+	}
+
+	if _, err := driver.Send(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := srv.Data()
+	for _, line := range strings.Split(data, "\n") {
+		if len(strings.TrimRight(line, "\r")) > 76 {
+			t.Fatalf("expected base64 lines of at most 76 characters, got %d", len(line))
+		}
+	}
+	encoded := base64.StdEncoding.EncodeToString(fileContent)
+	if !strings.Contains(strings.ReplaceAll(data, "\n", ""), encoded[:200]) {
+		t.Error("expected file attachment content in message")
+	}
+	if !strings.Contains(data, base64.StdEncoding.EncodeToString([]byte("streamed content"))) {
+		t.Error("expected reader attachment content in message")
+	}
+
+	// 预估大小与实际写入一致，并通过 SIZE 参数声明
+	commands := strings.Join(srv.Commands(), "\n")
+	if want := fmt.Sprintf("SIZE=%d", body.Size()); !strings.Contains(commands, want) {
+		t.Errorf("expected %s, got:\n%s", want, commands)
+	}
+	// 服务器读取时将 CRLF 转换为 LF
+	if got := int64(len(data) + strings.Count(data, "\n")); got != body.Size() {
+		t.Errorf("expected estimated size %d to match written size %d", body.Size(), got)
+	}
+}
+
+func TestSMTPDriver_Send_StreamingAttachmentTooLarge(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":             srv.host,
+		"port":             srv.port,
+		"max_message_size": "1KB",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	// 大小未知的 Reader 在写入过程中检查上限
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "from@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+		Attachments: []Attachment{
+			{Filename: "big.bin", Reader: &onceReader{r: bytes.NewReader(make([]byte, 4096))}},
+		},
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+	if srv.Data() != "" {
+		t.Error("expected aborted message not to be delivered")
+	}
+}
+
+func TestSMTPDriver_Send_DetectsAttachmentType(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{"host": srv.host, "port": srv.port})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "from@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+		Attachments: []Attachment{
+			{Filename: "logo", Content: []byte("\x89PNG\r\n\x1a\n\x00\x00")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(srv.Data(), `Content-Type: image/png; name="logo"`) {
+		t.Errorf("expected sniffed content type, got:\n%s", srv.Data())
+	}
+}
@@ -0,0 +1,322 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DriverTencent 腾讯云邮件推送（SES）驱动名称
+	DriverTencent = "tencent"
+
+	// TencentDefaultEndpoint 腾讯云 SES API 默认地址
+	TencentDefaultEndpoint = "https://ses.tencentcloudapi.com"
+
+	// TencentDefaultRegion 默认区域
+	TencentDefaultRegion = "ap-guangzhou"
+
+	// TencentDefaultMaxMessageSize 腾讯云 API 请求包大小上限（8MB，附件 base64 编码后计入）
+	TencentDefaultMaxMessageSize = 8 << 20
+
+	// TencentTriggerTransactional 触发类邮件（验证码、通知等即时发送）
+	TencentTriggerTransactional = "transactional"
+
+	// TencentTriggerBatch 非触发类邮件（营销、批量等非即时发送）
+	TencentTriggerBatch = "batch"
+
+	// tencentAPIVersion SES API 版本
+	tencentAPIVersion = "2020-10-02"
+)
+
+// TencentConfig 腾讯云 SES 驱动配置
+type TencentConfig struct {
+	// SecretID 访问密钥 ID
+	SecretID string `mapstructure:"secret_id"`
+
+	// SecretKey 访问密钥
+	SecretKey string `mapstructure:"secret_key"`
+
+	// Region 区域（可选，默认 ap-guangzhou）
+	Region string `mapstructure:"region"`
+
+	// Endpoint API 地址（可选，默认 https://ses.tencentcloudapi.com）
+	Endpoint string `mapstructure:"endpoint"`
+
+	// TriggerType 邮件触发类型（可选，transactional 或 batch，默认由腾讯云按非触发类处理）
+	TriggerType string `mapstructure:"trigger_type"`
+
+	// Timeout 请求超时时间（可选，默认 30s）
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxMessageSize 请求体最大字节数（可选，默认 8MB）
+	MaxMessageSize int64 `mapstructure:"max_message_size"`
+}
+
+// TencentDriver 腾讯云 SES 邮件驱动（SendEmail）
+type TencentDriver struct {
+	config *TencentConfig
+	client *http.Client
+	signer *tc3Signer
+}
+
+// NewTencentDriver 创建腾讯云 SES 驱动
+func NewTencentDriver(config map[string]any) (Driver, error) {
+	cfg := &TencentConfig{
+		Region:         TencentDefaultRegion,
+		Endpoint:       TencentDefaultEndpoint,
+		Timeout:        30 * time.Second,
+		MaxMessageSize: TencentDefaultMaxMessageSize,
+	}
+
+	// 解析配置
+	if secretID, ok := config["secret_id"].(string); ok {
+		cfg.SecretID = secretID
+	}
+	if secretKey, ok := config["secret_key"].(string); ok {
+		cfg.SecretKey = secretKey
+	}
+	if region, ok := config["region"].(string); ok && region != "" {
+		cfg.Region = region
+	}
+	if endpoint, ok := config["endpoint"].(string); ok && endpoint != "" {
+		cfg.Endpoint = strings.TrimRight(endpoint, "/")
+	}
+	if triggerType, ok := config["trigger_type"].(string); ok {
+		cfg.TriggerType = triggerType
+	}
+	if timeout, ok := config["timeout"].(string); ok {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			cfg.Timeout = d
+		}
+	}
+	if size, ok := parseByteSize(config["max_message_size"]); ok {
+		cfg.MaxMessageSize = size
+	}
+
+	driver := &TencentDriver{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		signer: &tc3Signer{
+			secretID:  cfg.SecretID,
+			secretKey: cfg.SecretKey,
+			service:   "ses",
+		},
+	}
+
+	if err := driver.Validate(); err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+// Name 驱动名称
+func (d *TencentDriver) Name() string {
+	return DriverTencent
+}
+
+// Validate 验证配置
+func (d *TencentDriver) Validate() error {
+	if d.config.SecretID == "" || d.config.SecretKey == "" {
+		return ErrDriverConfig.WithMsg("腾讯云 SecretID 与 SecretKey 不能为空")
+	}
+	switch d.config.TriggerType {
+	case "", TencentTriggerTransactional, TencentTriggerBatch:
+	default:
+		return ErrDriverConfig.WithMsgf("腾讯云 TriggerType 无效: %s", d.config.TriggerType)
+	}
+	if d.config.MaxMessageSize < 0 {
+		return ErrDriverConfig.WithMsg("腾讯云 MaxMessageSize 无效")
+	}
+	return nil
+}
+
+// Send 发送邮件
+func (d *TencentDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := msg.requireImmediate(); err != nil {
+		return nil, err
+	}
+
+	// 构建请求体
+	payload, err := d.buildPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.doRequest(ctx, payload)
+}
+
+// buildPayload 构建 SendEmail 请求体
+// 设置 TemplateID 时使用模板发送，否则发送 base64 编码的 HTML/纯文本正文
+func (d *TencentDriver) buildPayload(msg *Message) (map[string]any, error) {
+	payload := map[string]any{
+		"FromEmailAddress": displayAddress(msg.FromName, msg.From),
+		"Destination":      headerAddresses(msg.To),
+		"Subject":          msg.Subject,
+	}
+	if len(msg.Cc) > 0 {
+		payload["Cc"] = headerAddresses(msg.Cc)
+	}
+	if len(msg.Bcc) > 0 {
+		payload["Bcc"] = headerAddresses(msg.Bcc)
+	}
+	if msg.ReplyTo != "" {
+		payload["ReplyToAddresses"] = headerAddress(msg.ReplyTo)
+	}
+
+	switch d.config.TriggerType {
+	case TencentTriggerTransactional:
+		payload["TriggerType"] = 1
+	case TencentTriggerBatch:
+		payload["TriggerType"] = 0
+	}
+
+	if msg.TemplateID != "" {
+		templateID, err := strconv.ParseUint(msg.TemplateID, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidMessage.Wrap(err).WithMsgf("腾讯云模板 ID 无效: %s", msg.TemplateID)
+		}
+		templateData := "{}"
+		if len(msg.TemplateData) > 0 {
+			data, err := json.Marshal(msg.TemplateData)
+			if err != nil {
+				return nil, ErrInvalidMessage.Wrap(err).WithMsg("序列化模板变量失败")
+			}
+			templateData = string(data)
+		}
+		payload["Template"] = map[string]any{
+			"TemplateID":   templateID,
+			"TemplateData": templateData,
+		}
+	} else {
+		simple := map[string]string{}
+		if msg.BodyHTML != "" {
+			simple["Html"] = base64.StdEncoding.EncodeToString([]byte(msg.BodyHTML))
+		}
+		if msg.BodyText != "" {
+			simple["Text"] = base64.StdEncoding.EncodeToString([]byte(msg.BodyText))
+		}
+		payload["Simple"] = simple
+	}
+
+	// 附件（不支持内联图片）
+	if len(msg.Attachments) > 0 {
+		attachments := make([]map[string]string, 0, len(msg.Attachments))
+		for i := range msg.Attachments {
+			att := &msg.Attachments[i]
+			if att.Inline {
+				return nil, ErrInvalidMessage.WithMsgf("腾讯云 SES 不支持内联附件: %s", att.Filename)
+			}
+			// API 请求需要完整内容，流式附件在此读取
+			data, err := att.ReadAll()
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, map[string]string{
+				"FileName": att.Filename,
+				"Content":  base64.StdEncoding.EncodeToString(data),
+			})
+		}
+		payload["Attachments"] = attachments
+	}
+
+	return payload, nil
+}
+
+// doRequest 发送签名后的 SendEmail 请求
+func (d *TencentDriver) doRequest(ctx context.Context, payload map[string]any) (*Result, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("序列化请求失败")
+	}
+	if max := d.config.MaxMessageSize; max > 0 && int64(len(body)) > max {
+		return nil, ErrMessageTooLarge.WithMsgf("邮件大小 %d 字节超过限制 %d 字节", len(body), max)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.config.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("创建请求失败")
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-TC-Action", "SendEmail")
+	req.Header.Set("X-TC-Version", tencentAPIVersion)
+	req.Header.Set("X-TC-Region", d.config.Region)
+	d.signer.sign(req, body, time.Now())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrTimeout.Wrap(err)
+		}
+		return nil, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSendFailed.Wrap(err).WithMsg("读取响应失败")
+	}
+
+	// 解析响应
+	return d.parseResponse(resp, respBody)
+}
+
+// parseResponse 解析 SendEmail 响应
+// 腾讯云 API 3.0 出错时同样返回 200，错误信息在 Response.Error 中
+func (d *TencentDriver) parseResponse(resp *http.Response, body []byte) (*Result, error) {
+	var out struct {
+		Response struct {
+			MessageID string `json:"MessageId"`
+			RequestID string `json:"RequestId"`
+			Error     *struct {
+				Code    string `json:"Code"`
+				Message string `json:"Message"`
+			} `json:"Error"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		tcErr := &TencentError{
+			StatusCode: resp.StatusCode,
+			Code:       http.StatusText(resp.StatusCode),
+			Message:    strings.TrimSpace(string(body)),
+		}
+		return nil, tencentErrorCode(tcErr).Wrap(tcErr).WithMsgf("腾讯云 API 错误 (%d): %s", resp.StatusCode, tcErr.Message)
+	}
+
+	if e := out.Response.Error; e != nil || resp.StatusCode >= 300 {
+		tcErr := &TencentError{
+			StatusCode: resp.StatusCode,
+			RequestID:  out.Response.RequestID,
+		}
+		if e != nil {
+			tcErr.Code = e.Code
+			tcErr.Message = e.Message
+		} else {
+			tcErr.Code = http.StatusText(resp.StatusCode)
+		}
+		return nil, tencentErrorCode(tcErr).Wrap(tcErr).WithMsgf("腾讯云 API 错误: %s - %s", tcErr.Code, tcErr.Message)
+	}
+
+	return &Result{
+		MessageID: out.Response.MessageID,
+		Status:    "sent",
+		Success:   true,
+	}, nil
+}
+
+func init() {
+	// 注册腾讯云驱动到默认注册表
+	RegisterDriver(DriverTencent, NewTencentDriver)
+}
@@ -0,0 +1,62 @@
+package email
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KOMKZ/go-yogan-framework/errcode"
+)
+
+// TencentError 腾讯云 API 返回的错误，可通过 errors.As 获取
+type TencentError struct {
+	// StatusCode HTTP 状态码（API 3.0 出错时通常仍为 200）
+	StatusCode int
+
+	// Code 错误码（如 FailedOperation.IncorrectEmail、AuthFailure.SignatureFailure）
+	Code string
+
+	// Message 错误描述
+	Message string
+
+	// RequestID 请求 ID，用于向腾讯云排查问题
+	RequestID string
+}
+
+// Error 实现 error 接口
+func (e *TencentError) Error() string {
+	return fmt.Sprintf("tencent %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// tencentErrorCode 将腾讯云错误码映射为组件错误码
+func tencentErrorCode(e *TencentError) *errcode.AppError {
+	switch e.Code {
+	case "FailedOperation.IncorrectEmail", "FailedOperation.EmailAddrInBlacklist",
+		"FailedOperation.ReceiverHasUnsubscribed", "InvalidParameterValue.ReceiverEmailInvalid":
+		return ErrInvalidRecipient
+	case "FailedOperation.IncorrectSender", "FailedOperation.NotAuthenticatedSender",
+		"FailedOperation.InvalidTemplateID", "FailedOperation.TemplateContentNotApproved",
+		"InvalidParameterValue.SenderEmailInvalid":
+		// 发信地址或模板未在控制台配置
+		return ErrDriverConfig
+	case "FailedOperation.EmailContentToolarge", "FailedOperation.AttachContentToolarge":
+		return ErrMessageTooLarge
+	case "FailedOperation.FrequencyLimit", "FailedOperation.ExceedSendLimit":
+		return ErrRateLimited
+	}
+
+	switch {
+	case strings.HasPrefix(e.Code, "AuthFailure"), strings.HasPrefix(e.Code, "UnauthorizedOperation"):
+		return ErrAuthFailed
+	case strings.HasPrefix(e.Code, "RequestLimitExceeded"), strings.HasPrefix(e.Code, "LimitExceeded"):
+		return ErrRateLimited
+	case strings.HasPrefix(e.Code, "InvalidParameter"), strings.HasPrefix(e.Code, "MissingParameter"):
+		return ErrInvalidMessage
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuthFailed
+	default:
+		return ErrSendFailed
+	}
+}
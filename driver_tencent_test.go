@@ -0,0 +1,249 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewTencentDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantErr bool
+	}{
+		{
+			name:   "valid config",
+			config: map[string]any{"secret_id": "id", "secret_key": "key"},
+		},
+		{
+			name:   "batch trigger type",
+			config: map[string]any{"secret_id": "id", "secret_key": "key", "trigger_type": "batch"},
+		},
+		{
+			name:    "missing credentials",
+			config:  map[string]any{"secret_id": "id"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid trigger type",
+			config:  map[string]any{"secret_id": "id", "secret_key": "key", "trigger_type": "urgent"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTencentDriver(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTencentDriver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestTencentDriver 创建指向本地服务器的腾讯云驱动
+func newTestTencentDriver(t *testing.T, handler http.HandlerFunc) Driver {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	driver, err := NewTencentDriver(map[string]any{
+		"secret_id":    "AKIDEXAMPLE",
+		"secret_key":   "secret",
+		"region":       "ap-hongkong",
+		"endpoint":     server.URL,
+		"trigger_type": "transactional",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver
+}
+
+func TestTencentDriver_Send_Simple(t *testing.T) {
+	var payload map[string]any
+	var headers http.Header
+	driver := newTestTencentDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+		w.Write([]byte(`{"Response":{"MessageId":"msg-1","RequestId":"req-1"}}`))
+	})
+
+	result, err := driver.Send(context.Background(), &Message{
+		From:     "noreply@mail.example.com",
+		FromName: "Example",
+		To:       []string{"a@example.com"},
+		Cc:       []string{"cc@example.com"},
+		ReplyTo:  "support@example.com",
+		Subject:  "Test",
+		BodyHTML: "<p>Hello</p>",
+		BodyText: "Hello",
+		Attachments: []Attachment{
+			{Filename: "report.csv", Content: []byte("a,b")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessageID != "msg-1" || !result.Success {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if headers.Get("X-TC-Action") != "SendEmail" || headers.Get("X-TC-Version") != "2020-10-02" || headers.Get("X-TC-Region") != "ap-hongkong" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+	if auth := headers.Get("Authorization"); !strings.HasPrefix(auth, "TC3-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/ses/tc3_request") {
+		t.Errorf("unexpected authorization: %s", auth)
+	}
+
+	if payload["FromEmailAddress"] != `"Example" <noreply@mail.example.com>` {
+		t.Errorf("unexpected from: %v", payload["FromEmailAddress"])
+	}
+	if payload["ReplyToAddresses"] != "support@example.com" || len(payload["Cc"].([]any)) != 1 {
+		t.Errorf("unexpected reply-to/cc: %v", payload)
+	}
+	if payload["TriggerType"] != float64(1) {
+		t.Errorf("unexpected trigger type: %v", payload["TriggerType"])
+	}
+	simple := payload["Simple"].(map[string]any)
+	if simple["Html"] != base64.StdEncoding.EncodeToString([]byte("<p>Hello</p>")) || simple["Text"] != base64.StdEncoding.EncodeToString([]byte("Hello")) {
+		t.Errorf("unexpected simple content: %v", simple)
+	}
+	att := payload["Attachments"].([]any)[0].(map[string]any)
+	if att["FileName"] != "report.csv" || att["Content"] != base64.StdEncoding.EncodeToString([]byte("a,b")) {
+		t.Errorf("unexpected attachment: %v", att)
+	}
+}
+
+func TestTencentDriver_Send_Template(t *testing.T) {
+	var payload map[string]any
+	driver := newTestTencentDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`{"Response":{"MessageId":"msg-1","RequestId":"req-1"}}`))
+	})
+
+	_, err := driver.Send(context.Background(), &Message{
+		From:         "noreply@mail.example.com",
+		To:           []string{"a@example.com"},
+		Subject:      "Verify",
+		TemplateID:   "100091",
+		TemplateData: map[string]any{"code": "123456"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := payload["Simple"]; ok {
+		t.Error("expected no Simple content for template message")
+	}
+	template := payload["Template"].(map[string]any)
+	if template["TemplateID"] != float64(100091) || template["TemplateData"] != `{"code":"123456"}` {
+		t.Errorf("unexpected template: %v", template)
+	}
+}
+
+func TestTencentDriver_Send_InvalidMessage(t *testing.T) {
+	driver := newTestTencentDriver(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	tests := []struct {
+		name string
+		msg  *Message
+	}{
+		{"non-numeric template", &Message{From: "a@example.com", To: []string{"b@example.com"}, Subject: "s", TemplateID: "welcome"}},
+		{"inline attachment", &Message{From: "a@example.com", To: []string{"b@example.com"}, Subject: "s", BodyHTML: "h",
+			Attachments: []Attachment{{Filename: "logo.png", Content: []byte("png"), Inline: true, ContentID: "logo"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := driver.Send(context.Background(), tt.msg); !errors.Is(err, ErrInvalidMessage) {
+				t.Errorf("expected ErrInvalidMessage, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTencentDriver_Send_TooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	}))
+	t.Cleanup(server.Close)
+
+	driver, err := NewTencentDriver(map[string]any{
+		"secret_id":        "AKIDEXAMPLE",
+		"secret_key":       "secret",
+		"endpoint":         server.URL,
+		"max_message_size": "4KB",
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	if cfg := driver.(*TencentDriver).config; cfg.MaxMessageSize != 4<<10 {
+		t.Errorf("expected max message size 4KB, got %d", cfg.MaxMessageSize)
+	}
+
+	// 附件 base64 编码后超过请求体上限
+	_, err = driver.Send(context.Background(), &Message{
+		From:        "noreply@example.com",
+		To:          []string{"user@example.com"},
+		Subject:     "Report",
+		BodyText:    "See attached",
+		Attachments: []Attachment{{Filename: "report.csv", Content: []byte(strings.Repeat("a", 4<<10))}},
+	})
+	if !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("expected ErrMessageTooLarge, got %v", err)
+	}
+}
+
+func TestTencentDriver_Send_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{"bad signature", "AuthFailure.SignatureFailure", ErrAuthFailed},
+		{"bad recipient", "FailedOperation.IncorrectEmail", ErrInvalidRecipient},
+		{"unverified sender", "FailedOperation.NotAuthenticatedSender", ErrDriverConfig},
+		{"frequency limit", "FailedOperation.FrequencyLimit", ErrRateLimited},
+		{"request limit", "RequestLimitExceeded", ErrRateLimited},
+		{"invalid parameter", "InvalidParameterValue.TemplateDataError", ErrInvalidMessage},
+		{"internal error", "InternalError", ErrSendFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := newTestTencentDriver(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Response":{"Error":{"Code":"` + tt.code + `","Message":"error"},"RequestId":"req-1"}}`))
+			})
+
+			_, err := driver.Send(context.Background(), &Message{
+				From:     "noreply@mail.example.com",
+				To:       []string{"a@example.com"},
+				Subject:  "Test",
+				BodyText: "Hello",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var tcErr *TencentError
+			if !errors.As(err, &tcErr) || tcErr.Code != tt.code || tcErr.RequestID != "req-1" {
+				t.Errorf("expected TencentError %s, got %v", tt.code, tcErr)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistry_Tencent(t *testing.T) {
+	if !DefaultRegistry.Has(DriverTencent) {
+		t.Error("expected tencent driver to be registered")
+	}
+}
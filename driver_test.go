@@ -2,7 +2,11 @@ package email
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // MockDriver 模拟驱动
@@ -10,6 +14,9 @@ type MockDriver struct {
 	name       string
 	sendResult *Result
 	sendErr    error
+
+	// sent 最近一次收到的消息
+	sent *Message
 }
 
 func (d *MockDriver) Name() string {
@@ -17,6 +24,7 @@ func (d *MockDriver) Name() string {
 }
 
 func (d *MockDriver) Send(ctx context.Context, msg *Message) (*Result, error) {
+	d.sent = msg
 	if d.sendErr != nil {
 		return nil, d.sendErr
 	}
@@ -89,3 +97,47 @@ func TestDefaultRegistry(t *testing.T) {
 		t.Error("expected DefaultRegistry to have Mandrill driver")
 	}
 }
+
+func TestDrivers_RejectSendAt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	// 不支持定时发送的驱动不能忽略 SendAt 而立即投递
+	tests := []struct {
+		name   string
+		create DriverFactory
+		config map[string]any
+	}{
+		{"smtp", NewSMTPDriver, map[string]any{"host": "127.0.0.1", "port": 1}},
+		{"smtp mx", NewSMTPDriver, map[string]any{"mode": SMTPModeMX}},
+		{"lmtp", NewLMTPDriver, map[string]any{"host": "127.0.0.1", "port": 1}},
+		{"ses", NewSESDriver, map[string]any{"region": "us-east-1", "access_key_id": "id", "secret_access_key": "secret", "endpoint": server.URL}},
+		{"aliyun", NewAliyunDriver, map[string]any{"access_key_id": "id", "access_key_secret": "secret", "endpoint": server.URL}},
+		{"tencent", NewTencentDriver, map[string]any{"secret_id": "id", "secret_key": "key", "endpoint": server.URL}},
+		{"postmark", NewPostmarkDriver, map[string]any{"server_token": "token", "base_url": server.URL}},
+		{"graph", NewGraphDriver, map[string]any{"tenant_id": "t", "client_id": "c", "client_secret": "s", "token_url": server.URL, "base_url": server.URL}},
+		{"gmail", NewGmailDriver, map[string]any{"client_id": "c", "client_secret": "s", "refresh_token": "r", "token_url": server.URL, "base_url": server.URL}},
+		{"sendmail", NewSendmailDriver, map[string]any{"path": "/nonexistent/sendmail"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := tt.create(tt.config)
+			if err != nil {
+				t.Fatalf("failed to create driver: %v", err)
+			}
+			_, err = driver.Send(context.Background(), &Message{
+				From:     "sender@example.com",
+				To:       []string{"user@example.com"},
+				Subject:  "Later",
+				BodyText: "Hello",
+				SendAt:   time.Now().Add(time.Hour),
+			})
+			if !errors.Is(err, ErrInvalidMessage) {
+				t.Errorf("expected ErrInvalidMessage, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DSN NOTIFY 取值（RFC 3461）
+const (
+	DSNNotifySuccess = "SUCCESS"
+	DSNNotifyFailure = "FAILURE"
+	DSNNotifyDelay   = "DELAY"
+	DSNNotifyNever   = "NEVER"
+)
+
+// DSN RET 取值（RFC 3461）
+const (
+	// DSNReturnFull 退信中包含完整邮件
+	DSNReturnFull = "FULL"
+
+	// DSNReturnHeaders 退信中仅包含邮件头
+	DSNReturnHeaders = "HDRS"
+)
+
+// DSNOptions 投递状态通知选项（RFC 3461）
+// 仅在服务器声明 DSN 扩展时生效
+type DSNOptions struct {
+	// Notify 默认通知条件（作用于所有收件人）
+	Notify []string
+
+	// RecipientNotify 单个收件人的通知条件（覆盖 Notify）
+	RecipientNotify map[string][]string
+
+	// Return 退信内容: FULL, HDRS
+	Return string
+
+	// EnvelopeID 信封 ID，会原样出现在投递状态报告中
+	EnvelopeID string
+}
+
+// NotifyFor 获取指定收件人的通知条件
+func (o *DSNOptions) NotifyFor(rcpt string) []string {
+	if notify, ok := o.RecipientNotify[rcpt]; ok {
+		return notify
+	}
+	return o.Notify
+}
+
+// Validate 验证 DSN 选项
+func (o *DSNOptions) Validate() error {
+	if err := validateDSNNotify(o.Notify); err != nil {
+		return err
+	}
+	for _, notify := range o.RecipientNotify {
+		if err := validateDSNNotify(notify); err != nil {
+			return err
+		}
+	}
+	if o.Return != "" && o.Return != DSNReturnFull && o.Return != DSNReturnHeaders {
+		return ErrInvalidMessage.WithMsgf("DSN RET 无效: %s", o.Return)
+	}
+	for i := 0; i < len(o.EnvelopeID); i++ {
+		if o.EnvelopeID[i] < 32 || o.EnvelopeID[i] > 126 {
+			return ErrInvalidMessage.WithMsg("DSN ENVID 只能包含可打印 ASCII 字符")
+		}
+	}
+	return nil
+}
+
+// validateDSNNotify 验证 NOTIFY 取值，NEVER 不能与其他值同时使用
+func validateDSNNotify(notify []string) error {
+	for _, n := range notify {
+		switch n {
+		case DSNNotifySuccess, DSNNotifyFailure, DSNNotifyDelay:
+		case DSNNotifyNever:
+			if len(notify) > 1 {
+				return ErrInvalidMessage.WithMsg("DSN NOTIFY=NEVER 不能与其他值组合")
+			}
+		default:
+			return ErrInvalidMessage.WithMsgf("DSN NOTIFY 无效: %s", n)
+		}
+	}
+	return nil
+}
+
+// encodeXText 按 RFC 3461 xtext 编码参数值
+func encodeXText(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '+' || c == '=' {
+			buf.WriteString(fmt.Sprintf("+%02X", c))
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
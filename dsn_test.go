@@ -0,0 +1,84 @@
+package email
+
+import "testing"
+
+func TestDSNOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dsn     *DSNOptions
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			dsn: &DSNOptions{
+				Notify:     []string{DSNNotifySuccess, DSNNotifyFailure, DSNNotifyDelay},
+				Return:     DSNReturnHeaders,
+				EnvelopeID: "contract-42",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "never alone",
+			dsn:     &DSNOptions{Notify: []string{DSNNotifyNever}},
+			wantErr: false,
+		},
+		{
+			name:    "never combined",
+			dsn:     &DSNOptions{Notify: []string{DSNNotifyNever, DSNNotifyFailure}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid notify",
+			dsn:     &DSNOptions{Notify: []string{"ALWAYS"}},
+			wantErr: true,
+		},
+		{
+			name: "invalid recipient notify",
+			dsn: &DSNOptions{
+				RecipientNotify: map[string][]string{"user@example.com": {"ALWAYS"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid return",
+			dsn:     &DSNOptions{Return: "BODY"},
+			wantErr: true,
+		},
+		{
+			name:    "non-printable envelope id",
+			dsn:     &DSNOptions{EnvelopeID: "id\r\n"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dsn.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDSNOptions_NotifyFor(t *testing.T) {
+	dsn := &DSNOptions{
+		Notify: []string{DSNNotifyFailure},
+		RecipientNotify: map[string][]string{
+			"legal@example.com": {DSNNotifySuccess, DSNNotifyFailure},
+		},
+	}
+
+	if got := dsn.NotifyFor("legal@example.com"); len(got) != 2 {
+		t.Errorf("expected recipient override, got %v", got)
+	}
+	if got := dsn.NotifyFor("user@example.com"); len(got) != 1 || got[0] != DSNNotifyFailure {
+		t.Errorf("expected default notify, got %v", got)
+	}
+}
+
+func TestEncodeXText(t *testing.T) {
+	if got := encodeXText("a+b=c d"); got != "a+2Bb+3Dc+20d" {
+		t.Errorf("encodeXText() = %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package email
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/KOMKZ/go-yogan-framework/errcode"
@@ -33,4 +34,22 @@ var (
 
 	// ErrTimeout 请求超时
 	ErrTimeout = errcode.Register(errcode.New(ComponentCode, 1008, "email", "error.email.timeout", "请求超时", http.StatusGatewayTimeout))
+
+	// ErrMessageTooLarge 邮件超过大小限制
+	ErrMessageTooLarge = errcode.Register(errcode.New(ComponentCode, 1009, "email", "error.email.message_too_large", "邮件超过大小限制", http.StatusRequestEntityTooLarge))
+
+	// ErrAttachmentRejected 附件不符合策略（类型、扩展名或数量）
+	ErrAttachmentRejected = errcode.Register(errcode.New(ComponentCode, 1010, "email", "error.email.attachment_rejected", "附件不符合策略", http.StatusBadRequest))
+
+	// ErrAttachmentTooLarge 附件超过大小限制
+	ErrAttachmentTooLarge = errcode.Register(errcode.New(ComponentCode, 1011, "email", "error.email.attachment_too_large", "附件超过大小限制", http.StatusRequestEntityTooLarge))
+
+	// ErrRateLimited 请求被限流
+	ErrRateLimited = errcode.Register(errcode.New(ComponentCode, 1012, "email", "error.email.rate_limited", "请求被限流", http.StatusTooManyRequests))
 )
+
+// isAppError 是否为已分类的组件错误
+func isAppError(err error) bool {
+	var appErr *errcode.AppError
+	return errors.As(err, &appErr)
+}
@@ -8,6 +8,7 @@ require (
 	github.com/KOMKZ/go-yogan-framework v0.0.0
 	github.com/samber/do/v2 v2.0.0
 	go.uber.org/zap v1.27.1
+	golang.org/x/net v0.50.0
 )
 
 require (
@@ -16,6 +17,7 @@ require (
 	go.opentelemetry.io/otel v1.39.0 // indirect
 	go.opentelemetry.io/otel/trace v1.39.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 	gorm.io/gorm v1.31.1 // indirect
 )
@@ -1,6 +1,7 @@
 package email
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -15,6 +16,9 @@ type Manager struct {
 	drivers  map[string]Driver
 	logger   *logger.CtxZapLogger
 	mu       sync.RWMutex
+
+	// transformers 所有消息共用的附件转换器
+	transformers []AttachmentTransformer
 }
 
 // NewManager 创建邮件管理器
@@ -40,15 +44,31 @@ func NewManager(config *Config, log *logger.CtxZapLogger, registry *Registry) (*
 		}
 	}
 
-	return &Manager{
+	m := &Manager{
 		config:   config,
 		registry: registry,
 		drivers:  make(map[string]Driver),
 		logger:   log,
-	}, nil
+	}
+
+	// 附件压缩：未配置阈值时使用附件策略的单个附件上限
+	if config.Zip.Enabled {
+		threshold, err := config.zipThreshold()
+		if err != nil {
+			return nil, fmt.Errorf("invalid email config: %w", err)
+		}
+		m.transformers = append(m.transformers, &ZipTransformer{
+			Filename:  config.Zip.Filename,
+			Password:  config.Zip.Password,
+			Threshold: threshold,
+		})
+	}
+
+	return m, nil
 }
 
 // GetDriver 获取驱动实例
+// 直接调用驱动的 Send 不会应用默认发件人、附件转换与附件策略，需要时使用 Manager.Send
 func (m *Manager) GetDriver(name string) (Driver, error) {
 	m.mu.RLock()
 	driver, ok := m.drivers[name]
@@ -79,6 +99,11 @@ func (m *Manager) GetDriver(name string) (Driver, error) {
 		return nil, err
 	}
 
+	// 支持协议记录的驱动使用管理器的日志器输出
+	if aware, ok := driver.(debugLoggerAware); ok && m.logger != nil {
+		aware.SetDebugLogger(m.logger)
+	}
+
 	m.drivers[name] = driver
 
 	if m.logger != nil {
@@ -88,15 +113,97 @@ func (m *Manager) GetDriver(name string) (Driver, error) {
 	return driver, nil
 }
 
+// debugLoggerAware 可设置调试日志器的驱动
+type debugLoggerAware interface {
+	SetDebugLogger(log *logger.CtxZapLogger)
+}
+
+// Use 添加所有消息共用的附件转换器
+func (m *Manager) Use(transformers ...AttachmentTransformer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transformers = append(m.transformers, transformers...)
+}
+
 // New 创建邮件构建器
 func (m *Manager) New() *Builder {
+	m.mu.RLock()
+	transformers := append([]AttachmentTransformer(nil), m.transformers...)
+	m.mu.RUnlock()
+
 	return &Builder{
-		manager: m,
-		driver:  m.config.Default,
-		message: &Message{},
+		manager:      m,
+		driver:       m.config.Default,
+		message:      &Message{},
+		transformers: transformers,
 	}
 }
 
+// Send 使用指定驱动发送消息，driver 为空时使用默认驱动
+// 与 Builder.Send 相同，发送前应用默认发件人、附件转换器与附件策略；这些处理作用于副本，不修改 msg
+func (m *Manager) Send(ctx context.Context, driver string, msg *Message) (*Result, error) {
+	m.mu.RLock()
+	transformers := append([]AttachmentTransformer(nil), m.transformers...)
+	m.mu.RUnlock()
+
+	return m.send(ctx, driver, msg, transformers)
+}
+
+// send 发送流程：默认发件人、附件转换、附件策略检查，然后交给驱动
+func (m *Manager) send(ctx context.Context, driverName string, msg *Message, transformers []AttachmentTransformer) (*Result, error) {
+	// 在副本上处理：转换器会替换附件（如压缩包指向发送后删除的临时文件），
+	// 调用方的 Message 保持不变，可以再次发送
+	copied := *msg
+	copied.Attachments = append([]Attachment(nil), msg.Attachments...)
+	msg = &copied
+
+	// 应用默认发件人
+	if msg.From == "" && m.config.DefaultFrom != "" {
+		msg.From = m.config.DefaultFrom
+	}
+	if msg.FromName == "" && m.config.DefaultFromName != "" {
+		msg.FromName = m.config.DefaultFromName
+	}
+
+	// 转换前按原始附件检查类型，避免被禁止的文件经压缩后绕过策略
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+	if err := m.config.Attachments.checkTypes(msg.Attachments); err != nil {
+		return nil, err
+	}
+
+	// 附件转换（临时资源在发送结束后释放）
+	for _, t := range transformers {
+		cleanup, err := t.Transform(msg)
+		if err != nil {
+			return nil, err
+		}
+		if cleanup != nil {
+			defer cleanup()
+		}
+	}
+
+	// 附件策略：转换后的附件（含压缩包本身）按完整策略检查
+	if err := msg.DetectContentTypes(); err != nil {
+		return nil, err
+	}
+	if err := m.config.Attachments.Check(msg.Attachments); err != nil {
+		return nil, err
+	}
+
+	// 获取驱动
+	if driverName == "" {
+		driverName = m.config.Default
+	}
+	driver, err := m.GetDriver(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.Send(ctx, msg)
+}
+
 // Close 关闭管理器
 func (m *Manager) Close() error {
 	m.mu.Lock()
@@ -1,5 +1,10 @@
 package email
 
+import (
+	"io"
+	"time"
+)
+
 // Message 邮件消息（厂商无关）
 type Message struct {
 	// From 发件人地址
@@ -8,6 +13,12 @@ type Message struct {
 	// FromName 发件人名称
 	FromName string
 
+	// EnvelopeFrom 信封发件人（Return-Path，接收退信），为空时使用 From
+	EnvelopeFrom string
+
+	// VERP 是否将收件人编码进信封发件人（如 bounces+user=example.com@ours.com），用于退信归因
+	VERP bool
+
 	// To 收件人列表
 	To []string
 
@@ -34,8 +45,39 @@ type Message struct {
 
 	// Headers 自定义头
 	Headers map[string]string
+
+	// Tags 消息标签（用于厂商侧统计与事件归类，驱动支持时生效）
+	Tags map[string]string
+
+	// Categories 消息分类（驱动支持时生效，如 SendGrid categories）
+	Categories []string
+
+	// Importance 邮件重要性（驱动支持时生效，如 Graph importance），为空时使用默认值
+	Importance Importance
+
+	// TemplateID 厂商侧模板 ID（驱动支持时生效），设置后可不提供正文
+	TemplateID string
+
+	// TemplateData 厂商侧模板变量
+	TemplateData map[string]any
+
+	// SendAt 定时发送时间，零值表示立即发送；不支持定时发送的驱动返回 ErrInvalidMessage
+	SendAt time.Time
+
+	// DSN 投递状态通知选项（可选，驱动支持时生效）
+	DSN *DSNOptions
 }
 
+// Importance 邮件重要性
+type Importance string
+
+// 邮件重要性取值
+const (
+	ImportanceLow    Importance = "low"
+	ImportanceNormal Importance = "normal"
+	ImportanceHigh   Importance = "high"
+)
+
 // Attachment 附件
 type Attachment struct {
 	// Filename 文件名
@@ -44,6 +86,15 @@ type Attachment struct {
 	// Content 内容
 	Content []byte
 
+	// Reader 流式内容来源（可选，优先于 Content），发送时边读边编码
+	Reader io.Reader
+
+	// Path 本地文件路径（可选，优先于 Reader 与 Content），发送时打开
+	Path string
+
+	// Size Reader 的内容字节数（可选，用于发送前的大小检查）
+	Size int64
+
 	// ContentType MIME 类型
 	ContentType string
 
@@ -52,6 +103,10 @@ type Attachment struct {
 
 	// ContentID 内联 ID（用于 <img src="cid:xxx">）
 	ContentID string
+
+	// readerUsed Reader 是否已被读取；readerOffset 首次读取时的位置，用于重复投递时回退
+	readerUsed   bool
+	readerOffset int64
 }
 
 // Result 发送结果
@@ -64,6 +119,48 @@ type Result struct {
 
 	// Success 是否成功
 	Success bool
+
+	// Host 实际投递的服务器（SMTP 中继模式下为 host:port）
+	Host string
+
+	// Recipients 逐个收件人的投递结果（驱动支持时填充）
+	Recipients []RecipientResult
+
+	// Domains 逐个收件人域名的投递结果（SMTP mx 模式下填充）
+	Domains []DomainResult
+}
+
+// DomainResult 单个收件人域名的投递结果
+type DomainResult struct {
+	// Domain 收件人域名
+	Domain string
+
+	// Host 实际投递的 MX 主机
+	Host string
+
+	// Success 是否投递成功
+	Success bool
+
+	// Message 失败原因
+	Message string
+}
+
+// RecipientResult 单个收件人的投递结果
+type RecipientResult struct {
+	// Email 收件人地址
+	Email string
+
+	// Accepted 是否被服务器接受
+	Accepted bool
+
+	// Code SMTP 基础响应码（如 250、550）
+	Code int
+
+	// EnhancedCode RFC 3463 增强状态码（如 5.1.1）
+	EnhancedCode string
+
+	// Message 服务器响应内容
+	Message string
 }
 
 // Validate 验证消息
@@ -74,8 +171,57 @@ func (m *Message) Validate() error {
 	if m.Subject == "" {
 		return ErrInvalidMessage.WithMsg("主题不能为空")
 	}
-	if m.BodyHTML == "" && m.BodyText == "" {
+	if m.TemplateID == "" && m.BodyHTML == "" && m.BodyText == "" {
 		return ErrInvalidMessage.WithMsg("邮件内容不能为空")
 	}
+	if m.EnvelopeFrom != "" {
+		if _, _, ok := splitAddress(m.EnvelopeFrom); !ok {
+			return ErrInvalidMessage.WithMsgf("信封发件人地址无效: %s", m.EnvelopeFrom)
+		}
+	}
+	if m.DSN != nil {
+		if err := m.DSN.Validate(); err != nil {
+			return err
+		}
+	}
+	switch m.Importance {
+	case "", ImportanceLow, ImportanceNormal, ImportanceHigh:
+	default:
+		return ErrInvalidMessage.WithMsgf("邮件重要性无效: %s", m.Importance)
+	}
 	return nil
 }
+
+// requireBody 不支持厂商模板的驱动调用，模板消息未提供正文时拒绝发送
+func (m *Message) requireBody() error {
+	if m.BodyHTML == "" && m.BodyText == "" {
+		return ErrInvalidMessage.WithMsgf("驱动不支持模板 %s，邮件内容不能为空", m.TemplateID)
+	}
+	return nil
+}
+
+// requireImmediate 不支持定时发送的驱动调用，设置了 SendAt 时拒绝发送，避免邮件被提前投递
+func (m *Message) requireImmediate() error {
+	if !m.SendAt.IsZero() {
+		return ErrInvalidMessage.WithMsg("驱动不支持定时发送（SendAt）")
+	}
+	return nil
+}
+
+// DetectContentTypes 为未指定类型的附件推断 MIME 类型，各驱动在构建请求前调用
+func (m *Message) DetectContentTypes() error {
+	for i := range m.Attachments {
+		if err := m.Attachments[i].DetectContentType(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnvelopeSender 获取信封发件人，未设置 EnvelopeFrom 时使用 From
+func (m *Message) EnvelopeSender() string {
+	if m.EnvelopeFrom != "" {
+		return m.EnvelopeFrom
+	}
+	return m.From
+}
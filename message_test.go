@@ -1,6 +1,7 @@
 package email
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -52,6 +53,15 @@ func TestMessage_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "template without body",
+			msg: &Message{
+				To:         []string{"user@example.com"},
+				Subject:    "Test",
+				TemplateID: "42",
+			},
+			wantErr: false,
+		},
 		{
 			name: "empty recipient list",
 			msg: &Message{
@@ -72,3 +82,15 @@ func TestMessage_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestMessage_RequireBody(t *testing.T) {
+	msg := &Message{To: []string{"user@example.com"}, Subject: "Test", TemplateID: "42"}
+	if err := msg.requireBody(); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage for template without body, got %v", err)
+	}
+
+	msg.BodyText = "Hello"
+	if err := msg.requireBody(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
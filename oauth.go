@@ -0,0 +1,84 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oauthTokenExpiryDelta 令牌提前刷新的时间
+const oauthTokenExpiryDelta = time.Minute
+
+// oauthTokenCache 访问令牌缓存（Graph、Gmail 驱动共用），过期前复用
+type oauthTokenCache struct {
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// get 返回缓存的令牌，不存在或即将过期时调用 fetch 获取
+func (c *oauthTokenCache) get(ctx context.Context, fetch func(ctx context.Context) (string, time.Duration, error)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiry) {
+		return c.token, nil
+	}
+
+	token, expiresIn, err := fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expiry = time.Now().Add(expiresIn - oauthTokenExpiryDelta)
+	return c.token, nil
+}
+
+// reset 清除缓存的令牌（令牌被吊销时调用）
+func (c *oauthTokenCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
+
+// requestOAuthToken 向令牌地址提交表单（RFC 6749 第 4.4、6 节等），返回访问令牌与有效期
+func requestOAuthToken(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, ErrAuthFailed.Wrap(err).WithMsg("创建令牌请求失败")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", 0, ErrTimeout.Wrap(err)
+		}
+		return "", 0, ErrConnectionFailed.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, ErrAuthFailed.Wrap(err).WithMsg("读取令牌响应失败")
+	}
+
+	var out struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", 0, ErrAuthFailed.Wrap(err).WithMsgf("解析令牌响应失败 (%d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || out.AccessToken == "" {
+		return "", 0, ErrAuthFailed.WithMsgf("获取令牌失败: %s %s", out.Error, out.ErrorDescription)
+	}
+	return out.AccessToken, time.Duration(out.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,115 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// ContextDialer 支持 context 的拨号器（*net.Dialer 已实现）
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// newProxyDialer 根据代理地址创建拨号器
+// 支持 socks5://[user:pass@]host:port 与 http://[user:pass@]host:port（HTTP CONNECT）
+func newProxyDialer(rawURL string, forward *net.Dialer) (ContextDialer, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ErrDriverConfig.Wrap(err).WithMsgf("代理地址无效: %s", rawURL)
+	}
+	if u.Host == "" {
+		return nil, ErrDriverConfig.WithMsgf("代理地址缺少主机: %s", rawURL)
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", u.Host, auth, forward)
+		if err != nil {
+			return nil, ErrDriverConfig.Wrap(err).WithMsgf("创建 SOCKS5 代理失败: %s", u.Host)
+		}
+		return dialer.(ContextDialer), nil
+	case "http":
+		return &httpConnectDialer{proxyAddr: u.Host, user: u.User, forward: forward}, nil
+	default:
+		return nil, ErrDriverConfig.WithMsgf("不支持的代理协议: %s", u.Scheme)
+	}
+}
+
+// httpConnectDialer 通过 HTTP CONNECT 隧道建立连接
+type httpConnectDialer struct {
+	proxyAddr string
+	user      *url.Userinfo
+	forward   *net.Dialer
+}
+
+// DialContext 连接代理并建立到 addr 的隧道
+func (d *httpConnectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, network, d.proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// 握手期间应用 ctx 截止时间
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.user != nil {
+		password, _ := d.user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT %s: %s", addr, resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return &bufferedConn{Conn: conn, reader: br}, nil
+}
+
+// bufferedConn 优先读取握手阶段已缓冲的数据
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read 实现 io.Reader
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
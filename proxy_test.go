@@ -0,0 +1,292 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// startSOCKS5Proxy 启动一个需要用户名密码认证的进程内 SOCKS5 代理
+func startSOCKS5Proxy(t *testing.T, user, pass string) (string, *atomic.Int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start proxy: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var tunnels atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				target, err := socks5Handshake(conn, user, pass)
+				if err != nil {
+					return
+				}
+				tunnels.Add(1)
+				pipeConns(conn, target)
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), &tunnels
+}
+
+// socks5Handshake 处理 SOCKS5 协商、认证与 CONNECT 请求
+func socks5Handshake(conn net.Conn, user, pass string) (net.Conn, error) {
+	br := bufio.NewReader(conn)
+
+	// 方法协商
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(br, make([]byte, header[1])); err != nil {
+		return nil, err
+	}
+	conn.Write([]byte{0x05, 0x02})
+
+	// 用户名密码认证（RFC 1929）
+	ver := make([]byte, 2)
+	if _, err := io.ReadFull(br, ver); err != nil {
+		return nil, err
+	}
+	u := make([]byte, ver[1])
+	io.ReadFull(br, u)
+	plen, _ := br.ReadByte()
+	p := make([]byte, plen)
+	io.ReadFull(br, p)
+	if string(u) != user || string(p) != pass {
+		conn.Write([]byte{0x01, 0x01})
+		return nil, errors.New("auth failed")
+	}
+	conn.Write([]byte{0x01, 0x00})
+
+	// CONNECT 请求
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(br, req); err != nil {
+		return nil, err
+	}
+	var host string
+	switch req[3] {
+	case 0x01:
+		ip := make([]byte, 4)
+		io.ReadFull(br, ip)
+		host = net.IP(ip).String()
+	case 0x03:
+		l, _ := br.ReadByte()
+		name := make([]byte, l)
+		io.ReadFull(br, name)
+		host = string(name)
+	default:
+		return nil, fmt.Errorf("unsupported address type %d", req[3])
+	}
+	portBytes := make([]byte, 2)
+	io.ReadFull(br, portBytes)
+	port := binary.BigEndian.Uint16(portBytes)
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return nil, err
+	}
+	conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+	return target, nil
+}
+
+// startHTTPConnectProxy 启动一个需要 Basic 认证的进程内 HTTP CONNECT 代理
+func startHTTPConnectProxy(t *testing.T, user, pass string) (string, *atomic.Int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start proxy: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	var tunnels atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				req, err := http.ReadRequest(bufio.NewReader(conn))
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				if req.Header.Get("Proxy-Authorization") != wantAuth {
+					conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+					return
+				}
+				target, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+					return
+				}
+				conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+				tunnels.Add(1)
+				pipeConns(conn, target)
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), &tunnels
+}
+
+// pipeConns 双向转发数据直到任一方关闭
+func pipeConns(a, b net.Conn) {
+	defer b.Close()
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func TestSMTPDriver_Send_ThroughProxy(t *testing.T) {
+	socksAddr, socksTunnels := startSOCKS5Proxy(t, "user", "secret")
+	httpAddr, httpTunnels := startHTTPConnectProxy(t, "user", "secret")
+
+	tests := []struct {
+		name    string
+		proxy   string
+		tunnels *atomic.Int32
+	}{
+		{name: "socks5", proxy: "socks5://user:secret@" + socksAddr, tunnels: socksTunnels},
+		{name: "http connect", proxy: "http://user:secret@" + httpAddr, tunnels: httpTunnels},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startScriptedSMTPServer(t, nil)
+
+			driver, err := NewSMTPDriver(map[string]any{
+				"host":  srv.host,
+				"port":  srv.port,
+				"proxy": tt.proxy,
+			})
+			if err != nil {
+				t.Fatalf("failed to create driver: %v", err)
+			}
+
+			_, err = driver.Send(context.Background(), &Message{
+				From:     "sender@example.com",
+				To:       []string{"to@example.com"},
+				Subject:  "Test",
+				BodyText: "Hello",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.tunnels.Load() != 1 {
+				t.Errorf("expected connection through proxy, got %d tunnels", tt.tunnels.Load())
+			}
+			if srv.Data() == "" {
+				t.Error("expected message to be delivered")
+			}
+		})
+	}
+}
+
+func TestSMTPDriver_Send_ProxyAuthFailed(t *testing.T) {
+	httpAddr, _ := startHTTPConnectProxy(t, "user", "secret")
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host":  srv.host,
+		"port":  srv.port,
+		"proxy": "http://user:wrong@" + httpAddr,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if !errors.Is(err, ErrConnectionFailed) {
+		t.Errorf("expected ErrConnectionFailed, got %v", err)
+	}
+}
+
+func TestNewSMTPDriver_InvalidProxy(t *testing.T) {
+	tests := []string{
+		"ftp://proxy.example.com:21",
+		"socks5://",
+		"://bad",
+	}
+
+	for _, proxyURL := range tests {
+		t.Run(proxyURL, func(t *testing.T) {
+			_, err := NewSMTPDriver(map[string]any{
+				"host":  "smtp.example.com",
+				"proxy": proxyURL,
+			})
+			if !errors.Is(err, ErrDriverConfig) {
+				t.Errorf("expected ErrDriverConfig, got %v", err)
+			}
+		})
+	}
+}
+
+// countingDialer 记录拨号次数的拨号器
+type countingDialer struct {
+	dials atomic.Int32
+}
+
+func (d *countingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.dials.Add(1)
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, addr)
+}
+
+func TestSMTPDriver_SetDialer(t *testing.T) {
+	srv := startScriptedSMTPServer(t, nil)
+
+	driver, err := NewSMTPDriver(map[string]any{
+		"host": srv.host,
+		"port": srv.port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	dialer := &countingDialer{}
+	driver.(*SMTPDriver).SetDialer(dialer)
+
+	_, err = driver.Send(context.Background(), &Message{
+		From:     "sender@example.com",
+		To:       []string{"to@example.com"},
+		Subject:  "Test",
+		BodyText: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dialer.dials.Load() != 1 {
+		t.Errorf("expected custom dialer to be used once, got %d", dialer.dials.Load())
+	}
+}
@@ -0,0 +1,41 @@
+package email
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTC3CanonicalRequest(t *testing.T) {
+	// 腾讯云签名文档中的示例（DescribeInstances）
+	body := []byte(`{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`)
+	req, _ := http.NewRequest(http.MethodPost, "https://cvm.tencentcloudapi.com/", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	canonical, signed := tc3CanonicalRequest(req, body)
+	if signed != "content-type;host" {
+		t.Errorf("unexpected signed headers: %s", signed)
+	}
+	if got := sha256Hex([]byte(canonical)); got != "5ffe6a04c0664d6b969fab9a13bdab201d63ee709638e2749d62a09ca18d7031" {
+		t.Errorf("unexpected canonical request hash: %s\n%s", got, canonical)
+	}
+}
+
+func TestTC3Signer_Sign(t *testing.T) {
+	signer := &tc3Signer{secretID: "AKIDEXAMPLE", secretKey: "secret", service: "ses"}
+	req, _ := http.NewRequest(http.MethodPost, "https://ses.tencentcloudapi.com/", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-TC-Action", "SendEmail")
+
+	signer.sign(req, []byte(`{}`), time.Unix(1551113065, 0))
+
+	if got := req.Header.Get("X-TC-Timestamp"); got != "1551113065" {
+		t.Errorf("unexpected timestamp: %s", got)
+	}
+	auth := req.Header.Get("Authorization")
+	prefix := "TC3-HMAC-SHA256 Credential=AKIDEXAMPLE/2019-02-25/ses/tc3_request, SignedHeaders=content-type;host;x-tc-action, Signature="
+	if !strings.HasPrefix(auth, prefix) || len(auth) != len(prefix)+64 {
+		t.Errorf("unexpected authorization: %s", auth)
+	}
+}